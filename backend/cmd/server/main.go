@@ -5,9 +5,12 @@ import (
 	"amar-dera/internal/core/services"
 	"amar-dera/internal/handlers"
 	"amar-dera/internal/infra/db"
+	"amar-dera/internal/infra/storage"
 	"amar-dera/internal/repositories/mongo"
 	"amar-dera/internal/router"
+	"context"
 	"log"
+	"time"
 )
 
 func main() {
@@ -21,29 +24,64 @@ func main() {
 	}
 	defer database.Disconnect()
 
+	// --- Migrations ---
+	if err := mongo.MigrateMoneyToPaisa(context.Background(), database.Database); err != nil {
+		log.Printf("Failed to migrate money fields: %v", err)
+	}
+	if err := mongo.MigrateMealSlots(context.Background(), database.Database); err != nil {
+		log.Printf("Failed to migrate meal slots: %v", err)
+	}
+	if err := mongo.MigrateMessOwners(context.Background(), database.Database); err != nil {
+		log.Printf("Failed to migrate mess owners: %v", err)
+	}
+	if err := mongo.EnsureIndexes(context.Background(), database.Database); err != nil {
+		log.Printf("Failed to create indexes: %v", err)
+	}
+
 	// --- Repositories ---
 	userRepo := mongo.NewUserRepository(database.Database)
 	messRepo := mongo.NewMessRepository(database.Database)
 	financeRepo := mongo.NewFinanceRepository(database.Database)
 	feedRepo := mongo.NewFeedRepository(database.Database)
+	ledgerRepo := mongo.NewLedgerRepository(database.Database)
+	attachmentRepo := mongo.NewAttachmentRepository(database.Database)
+	auditRepo := mongo.NewAuditRepository(database.Database)
+	inviteRepo := mongo.NewInviteRepository(database.Database)
+	transactor := mongo.NewTransactor(database.Database)
+
+	// --- Storage ---
+	fileStorage, err := storage.NewLocalStorage(cfg.UploadDir)
+	if err != nil {
+		log.Fatalf("Failed to prepare upload directory: %v", err)
+	}
 
 	// --- Services ---
-	userService := services.NewUserService(userRepo, cfg)
-	messService := services.NewMessService(messRepo, userRepo)
-	financeService := services.NewFinanceService(financeRepo, messRepo, userRepo)
+	userService := services.NewUserService(userRepo, messRepo, cfg)
+	auditService := services.NewAuditService(auditRepo, messRepo)
+	ledgerService := services.NewLedgerService(ledgerRepo, financeRepo, messRepo)
+	financeService := services.NewFinanceService(financeRepo, messRepo, userRepo, ledgerService, transactor, auditService)
+	messService := services.NewMessService(messRepo, userRepo, inviteRepo, financeService, transactor, auditService)
 	feedService := services.NewFeedService(feedRepo, messRepo, userRepo)
+	attachmentService := services.NewAttachmentService(attachmentRepo, financeRepo, messRepo, fileStorage, cfg.MaxUploadMB<<20, transactor, auditService)
 
 	// --- Handlers ---
 	authHandler := handlers.NewAuthHandler(userService)
-	messHandler := handlers.NewMessHandler(messService)
+	messHandler := handlers.NewMessHandler(messService, financeService)
 	financeHandler := handlers.NewFinanceHandler(financeService)
 	feedHandler := handlers.NewFeedHandler(feedService)
+	ledgerHandler := handlers.NewLedgerHandler(ledgerService)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService)
+	auditHandler := handlers.NewAuditHandler(auditService)
 
 	// --- Background Services ---
 	services.StartLogCleaner()
+	services.StartCostTemplateScheduler(financeService)
+	services.StartMealScheduler(financeService)
+	services.StartTrashPurger(financeService, attachmentService, time.Duration(cfg.TrashRetention)*24*time.Hour)
+	services.StartJoinRequestExpirer(messService, time.Duration(cfg.JoinExpiry)*24*time.Hour)
 
 	// --- Router ---
-	r := router.NewRouter(cfg, authHandler, messHandler, financeHandler, feedHandler)
+	r := router.NewRouter(cfg, authHandler, messHandler, financeHandler, feedHandler, ledgerHandler, attachmentHandler, auditHandler, messService)
 
 	log.Printf("Server starting on port %s", cfg.Port)
 	if err := r.Run(":" + cfg.Port); err != nil {
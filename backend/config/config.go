@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
@@ -13,6 +14,10 @@ type Config struct {
 	DBName         string
 	JWTSecret      string
 	GoogleClientID string
+	UploadDir      string // Local directory for receipt attachments
+	MaxUploadMB    int64
+	TrashRetention int64 // Days deleted bazars and costs stay restorable
+	JoinExpiry     int64 // Days a join request stays pending before it expires
 }
 
 func LoadConfig() *Config {
@@ -28,6 +33,10 @@ func LoadConfig() *Config {
 		DBName:         getEnv("DB_NAME", "amar_dera"),
 		JWTSecret:      getEnv("JWT_SECRET", "super_secret_key"),
 		GoogleClientID: getEnv("GOOGLE_CLIENT_ID", ""),
+		UploadDir:      getEnv("UPLOAD_DIR", "uploads"),
+		MaxUploadMB:    getEnvInt("MAX_UPLOAD_MB", 5),
+		TrashRetention: getEnvInt("TRASH_RETENTION_DAYS", 30),
+		JoinExpiry:     getEnvInt("JOIN_REQUEST_EXPIRY_DAYS", 14),
 	}
 }
 
@@ -38,3 +47,17 @@ func getEnv(key, fallback string) string {
 	log.Printf("Using default config for %s: %s", key, fallback)
 	return fallback
 }
+
+func getEnvInt(key string, fallback int64) int64 {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		log.Printf("Using default config for %s: %d", key, fallback)
+		return fallback
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.Printf("Invalid %s %q, using default: %d", key, value, fallback)
+		return fallback
+	}
+	return n
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+type AttachmentEntity string
+
+const (
+	AttachmentEntityBazar       AttachmentEntity = "bazar"
+	AttachmentEntityServiceCost AttachmentEntity = "service_cost"
+)
+
+// Attachment is a receipt or other evidence stored for a finance document.
+// The file itself lives in FileStorage under StorageKey.
+type Attachment struct {
+	ID           string           `bson:"_id" json:"id"`
+	MessID       string           `bson:"mess_id" json:"mess_id"`
+	EntityType   AttachmentEntity `bson:"entity_type" json:"entity_type"`
+	EntityID     string           `bson:"entity_id" json:"entity_id"`
+	FileName     string           `bson:"file_name" json:"file_name"`
+	ContentType  string           `bson:"content_type" json:"content_type"`
+	Size         int64            `bson:"size" json:"size"`
+	StorageKey   string           `bson:"storage_key" json:"-"`
+	ThumbnailKey string           `bson:"thumbnail_key,omitempty" json:"-"`
+	HasThumbnail bool             `bson:"has_thumbnail" json:"has_thumbnail"`
+	UploadedBy   string           `bson:"uploaded_by" json:"uploaded_by"`
+	UploadedAt   time.Time        `bson:"uploaded_at" json:"uploaded_at"`
+}
+
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *Attachment) error
+	GetByID(ctx context.Context, id string) (*Attachment, error)
+	ListByEntity(ctx context.Context, messID string, entityType AttachmentEntity, entityID string) ([]Attachment, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// FileStorage stores uploaded files by key. The local-disk implementation
+// can be swapped for an S3-compatible one without touching services.
+type FileStorage interface {
+	Save(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
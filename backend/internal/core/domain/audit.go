@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditApprove AuditAction = "approve"
+	AuditReject  AuditAction = "reject"
+	AuditReverse AuditAction = "reverse"
+	AuditRefund  AuditAction = "refund"
+	AuditLock    AuditAction = "lock"
+	AuditUnlock  AuditAction = "unlock"
+	AuditClose   AuditAction = "close"
+	AuditSettle  AuditAction = "settle"
+	AuditRestore AuditAction = "restore"
+	AuditPurge   AuditAction = "purge"
+
+	// Membership changes
+	AuditJoin      AuditAction = "join"
+	AuditLeave     AuditAction = "leave"
+	AuditRemove    AuditAction = "remove"
+	AuditSuspend   AuditAction = "suspend"
+	AuditReinstate AuditAction = "reinstate"
+	AuditRevoke    AuditAction = "revoke"
+	AuditDissolve  AuditAction = "dissolve"
+)
+
+// Entity types recorded in the audit log
+const (
+	AuditEntityServiceCost  = "service_cost"
+	AuditEntityPayment      = "payment"
+	AuditEntityBazar        = "bazar"
+	AuditEntityDailyMeal    = "daily_meal"
+	AuditEntityMonthLock    = "month_lock"
+	AuditEntitySnapshot     = "summary_snapshot"
+	AuditEntitySettlement   = "settlement"
+	AuditEntityCostTemplate = "cost_template"
+	AuditEntityMealSchedule = "meal_schedule"
+	AuditEntityMealOff      = "meal_off"
+	AuditEntityMealConfig   = "meal_config"
+	AuditEntityAttachment   = "attachment"
+	AuditEntityMember       = "member"
+	AuditEntityInvite       = "invite"
+	AuditEntityMess         = "mess"
+)
+
+// AuditEntry records one change: who did what to which entity, with the
+// entity as JSON before and after. Entries are only ever appended.
+type AuditEntry struct {
+	ID         string          `bson:"_id" json:"id"`
+	MessID     string          `bson:"mess_id" json:"mess_id"`
+	Month      string          `bson:"month,omitempty" json:"month,omitempty"`
+	ActorID    string          `bson:"actor_id" json:"actor_id"` // "system" for background jobs
+	Action     AuditAction     `bson:"action" json:"action"`
+	EntityType string          `bson:"entity_type" json:"entity_type"`
+	EntityID   string          `bson:"entity_id" json:"entity_id"`
+	Before     json.RawMessage `bson:"before,omitempty" json:"before,omitempty"`
+	After      json.RawMessage `bson:"after,omitempty" json:"after,omitempty"`
+	RequestID  string          `bson:"request_id,omitempty" json:"request_id,omitempty"`
+	CreatedAt  time.Time       `bson:"created_at" json:"created_at"`
+}
+
+type AuditFilter struct {
+	MessID     string
+	Month      string
+	EntityType string
+	ActorID    string
+	Limit      int64
+}
+
+type AuditRepository interface {
+	Create(ctx context.Context, entry *AuditEntry) error
+	List(ctx context.Context, filter AuditFilter) ([]AuditEntry, error)
+}
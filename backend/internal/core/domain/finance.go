@@ -2,13 +2,36 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"time"
 )
 
+// ErrMonthLocked is returned by every finance write that targets a locked month.
+var ErrMonthLocked = errors.New("month is locked")
+
+// ErrForbidden is returned when the caller lacks the role an operation needs.
+var ErrForbidden = errors.New("forbidden")
+
+// ErrNotFound is returned for entities that do not exist, including ones that
+// belong to a different mess than the one addressed.
+var ErrNotFound = errors.New("not found")
+
+// ErrInvalid is returned when the input of a write fails validation.
+var ErrInvalid = errors.New("invalid input")
+
+// ErrDuplicate is returned when a write would store a second copy of
+// something that must be unique.
+var ErrDuplicate = errors.New("already exists")
+
+// ErrConflict is returned when an entity is not in a state that allows the
+// operation, such as settling a month that is still open.
+var ErrConflict = errors.New("conflict")
+
 // --- Service Costs (Fixed) ---
 type CostShare struct {
-	UserID string  `bson:"user_id" json:"user_id"`
-	Amount float64 `bson:"amount" json:"amount"`
+	UserID string `bson:"user_id" json:"user_id"`
+	Amount Money  `bson:"amount" json:"amount"`
 }
 
 type ServiceCost struct {
@@ -16,10 +39,45 @@ type ServiceCost struct {
 	MessID    string      `bson:"mess_id" json:"mess_id"`
 	Month     string      `bson:"month" json:"month"` // YYYY-MM
 	Name      string      `bson:"name" json:"name"`   // Gas, WiFi, etc.
-	Amount    float64     `bson:"amount" json:"amount"`
+	Amount    Money       `bson:"amount" json:"amount"`
 	Shares    []CostShare `bson:"shares,omitempty" json:"shares,omitempty"` // Optional: Custom split
 	CreatedBy string      `bson:"created_by" json:"created_by"`
 	Status    string      `bson:"status" json:"status"` // pending, approved
+
+	Attachments []string `bson:"attachments,omitempty" json:"attachments,omitempty"` // Receipt attachment IDs
+	TemplateID  string   `bson:"template_id,omitempty" json:"template_id,omitempty"` // Set when created from a CostTemplate
+
+	// Deleted costs stay in the trash until restored or purged
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy string     `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+}
+
+func (c *ServiceCost) IsDeleted() bool {
+	return c.DeletedAt != nil
+}
+
+// CostTemplate is a recurring service cost (rent, gas, WiFi...) that is
+// copied into every month from StartMonth through EndMonth (open-ended when
+// empty). Without Shares the cost is split like any un-shared ServiceCost.
+type CostTemplate struct {
+	ID         string      `bson:"_id" json:"id"`
+	MessID     string      `bson:"mess_id" json:"mess_id"`
+	Name       string      `bson:"name" json:"name"`
+	Amount     Money       `bson:"amount" json:"amount"`
+	Shares     []CostShare `bson:"shares,omitempty" json:"shares,omitempty"`
+	StartMonth string      `bson:"start_month" json:"start_month"` // YYYY-MM
+	EndMonth   string      `bson:"end_month,omitempty" json:"end_month,omitempty"`
+	CreatedBy  string      `bson:"created_by" json:"created_by"`
+	CreatedAt  time.Time   `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time   `bson:"updated_at" json:"updated_at"`
+
+	SkippedMonths []string `bson:"skipped_months,omitempty" json:"skipped_months,omitempty"` // Months whose copy was purged from the trash
+}
+
+// AppliesTo reports whether the template covers the month (YYYY-MM) and
+// was not skipped there.
+func (t CostTemplate) AppliesTo(month string) bool {
+	return t.StartMonth <= month && (t.EndMonth == "" || month <= t.EndMonth) && !slices.Contains(t.SkippedMonths, month)
 }
 
 // --- Payments (Cash In) ---
@@ -30,42 +88,185 @@ const (
 	PaymentTypeMeal  PaymentType = "meal"  // Bazar + Meal
 )
 
+type PaymentMethod string
+
+const (
+	PaymentMethodCash  PaymentMethod = "cash"
+	PaymentMethodBkash PaymentMethod = "bkash"
+	PaymentMethodNagad PaymentMethod = "nagad"
+	PaymentMethodBank  PaymentMethod = "bank"
+)
+
+func (m PaymentMethod) IsValid() bool {
+	switch m {
+	case PaymentMethodCash, PaymentMethodBkash, PaymentMethodNagad, PaymentMethodBank:
+		return true
+	}
+	return false
+}
+
+// PaymentKind tells regular payments apart from compensating entries.
+// Reversals and refunds carry negative amounts so summaries simply add them up.
+type PaymentKind string
+
+const (
+	PaymentKindPayment  PaymentKind = "payment"
+	PaymentKindReversal PaymentKind = "reversal" // Cancels an approved payment
+	PaymentKindRefund   PaymentKind = "refund"   // Cash paid out to a member
+)
+
 type Payment struct {
 	ID         string      `bson:"_id" json:"id"`
 	UserID     string      `bson:"user_id" json:"user_id"`
 	MessID     string      `bson:"mess_id" json:"mess_id"`
-	Amount     float64     `bson:"amount" json:"amount"`
+	Amount     Money       `bson:"amount" json:"amount"`
 	Type       PaymentType `bson:"type" json:"type"`
 	Status     string      `bson:"status" json:"status"` // pending, approved, rejected
 	Month      string      `bson:"month" json:"month"`
 	CreatedAt  time.Time   `bson:"created_at" json:"created_at"`
 	ApprovedBy string      `bson:"approved_by,omitempty" json:"approved_by,omitempty"`
+
+	Method         PaymentMethod `bson:"method,omitempty" json:"method,omitempty"`                   // cash, bkash, nagad, bank
+	TransactionRef string        `bson:"transaction_ref,omitempty" json:"transaction_ref,omitempty"` // Mobile banking / bank reference
+	SubmittedBy    string        `bson:"submitted_by,omitempty" json:"submitted_by,omitempty"`
+
+	Kind       PaymentKind `bson:"kind,omitempty" json:"kind,omitempty"` // Empty on legacy documents means payment
+	Reason     string      `bson:"reason,omitempty" json:"reason,omitempty"`
+	RejectedBy string      `bson:"rejected_by,omitempty" json:"rejected_by,omitempty"`
+	ReversalOf string      `bson:"reversal_of,omitempty" json:"reversal_of,omitempty"` // Original payment of a reversal entry
+	ReversedBy string      `bson:"reversed_by,omitempty" json:"reversed_by,omitempty"` // Reversal entry of an original payment
+
+	SettlementID string `bson:"settlement_id,omitempty" json:"settlement_id,omitempty"` // Set when recorded by a settlement transfer
+	SettlesMonth string `bson:"settles_month,omitempty" json:"settles_month,omitempty"` // Closed month whose balance this pays off, booked in a later month
 }
 
 // --- Bazar (Shopping) ---
+type BazarCategory string
+
+const (
+	BazarCategoryVegetables BazarCategory = "vegetables"
+	BazarCategoryFish       BazarCategory = "fish"
+	BazarCategoryMeat       BazarCategory = "meat"
+	BazarCategoryRice       BazarCategory = "rice"
+	BazarCategorySpices     BazarCategory = "spices"
+	BazarCategoryOil        BazarCategory = "oil"
+	BazarCategoryDairy      BazarCategory = "dairy"
+	BazarCategoryOther      BazarCategory = "other"
+	// Legacy entries without line items are reported under this category
+	BazarCategoryUncategorized BazarCategory = "uncategorized"
+)
+
+func (c BazarCategory) IsValid() bool {
+	switch c {
+	case BazarCategoryVegetables, BazarCategoryFish, BazarCategoryMeat, BazarCategoryRice,
+		BazarCategorySpices, BazarCategoryOil, BazarCategoryDairy, BazarCategoryOther:
+		return true
+	}
+	return false
+}
+
+type BazarItem struct {
+	Name      string        `bson:"name" json:"name"`
+	Quantity  float64       `bson:"quantity" json:"quantity"`
+	Unit      string        `bson:"unit" json:"unit"` // kg, litre, pcs, ...
+	UnitPrice Money         `bson:"unit_price" json:"unit_price"`
+	Total     Money         `bson:"total" json:"total"` // Defaults to Quantity x UnitPrice
+	Category  BazarCategory `bson:"category" json:"category"`
+}
+
 type Bazar struct {
-	ID        string    `bson:"_id" json:"id"`
-	MessID    string    `bson:"mess_id" json:"mess_id"`
-	BuyerID   string    `bson:"buyer_id" json:"buyer_id"`
-	Amount    float64   `bson:"amount" json:"amount"`
-	Items     string    `bson:"items" json:"items"`
-	Date      time.Time `bson:"date" json:"date"`
-	Status    string    `bson:"status" json:"status"` // pending, approved
-	Month     string    `bson:"month" json:"month"`
-	CreatedBy string    `bson:"created_by" json:"created_by"`
+	ID        string      `bson:"_id" json:"id"`
+	MessID    string      `bson:"mess_id" json:"mess_id"`
+	BuyerID   string      `bson:"buyer_id" json:"buyer_id"`
+	Amount    Money       `bson:"amount" json:"amount"`
+	Items     string      `bson:"items" json:"items"`                               // Free-text summary
+	LineItems []BazarItem `bson:"line_items,omitempty" json:"line_items,omitempty"` // Must add up to Amount
+	Date      time.Time   `bson:"date" json:"date"`
+	Status    string      `bson:"status" json:"status"` // pending, approved
+	Month     string      `bson:"month" json:"month"`
+	CreatedBy string      `bson:"created_by" json:"created_by"`
+
+	Attachments []string `bson:"attachments,omitempty" json:"attachments,omitempty"` // Receipt attachment IDs
+
+	// Deleted entries stay in the trash until restored or purged
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy string     `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+}
+
+func (b *Bazar) IsDeleted() bool {
+	return b.DeletedAt != nil
+}
+
+// SpendReportRow is the bazar spend of one item or category. Month is empty
+// on range totals; Unit and Quantity are only set when grouping by item.
+type SpendReportRow struct {
+	Month    string  `json:"month,omitempty"`
+	Key      string  `json:"key"`
+	Unit     string  `json:"unit,omitempty"`
+	Quantity float64 `json:"quantity,omitempty"`
+	Amount   Money   `json:"amount"`
+}
+
+type SpendReport struct {
+	GroupBy string           `json:"group_by"` // item, category
+	From    string           `json:"from"`
+	To      string           `json:"to"`
+	Rows    []SpendReportRow `json:"rows"`   // Per month
+	Totals  []SpendReportRow `json:"totals"` // Across the whole range
 }
 
 // --- Daily Meals ---
 type DailyMeal struct {
-	ID         string    `bson:"_id,omitempty" json:"id"`
-	MessID     string    `bson:"mess_id" json:"mess_id"`
-	UserID     string    `bson:"user_id" json:"user_id"`
-	Date       time.Time `bson:"date" json:"date"`
-	Breakfast  float64   `bson:"breakfast" json:"breakfast"` // 1, 0.5, 0
-	Lunch      float64   `bson:"lunch" json:"lunch"`
-	Dinner     float64   `bson:"dinner" json:"dinner"`
-	GuestMeals int       `bson:"guest_meals" json:"guest_meals"`
-	Month      string    `bson:"month" json:"month"`
+	ID         string             `bson:"_id,omitempty" json:"id"`
+	MessID     string             `bson:"mess_id" json:"mess_id"`
+	UserID     string             `bson:"user_id" json:"user_id"`
+	Date       time.Time          `bson:"date" json:"date"`
+	Meals      map[string]float64 `bson:"meals" json:"meals"` // Slot key -> count, e.g. {"breakfast": 0.5}
+	GuestMeals int                `bson:"guest_meals" json:"guest_meals"`
+	Month      string             `bson:"month" json:"month"`
+
+	// Deprecated: fixed slots from before configurable meals. Still accepted
+	// from and mirrored to older clients; Normalize folds them into Meals.
+	Breakfast float64 `bson:"-" json:"breakfast"`
+	Lunch     float64 `bson:"-" json:"lunch"`
+	Dinner    float64 `bson:"-" json:"dinner"`
+}
+
+// MealRowResult reports what happened to one row of a meal batch update.
+type MealRowResult struct {
+	Index  int       `json:"index"` // Position in the request
+	UserID string    `json:"user_id"`
+	Date   time.Time `json:"date"`
+	Status string    `json:"status"` // updated, rejected
+	Error  string    `json:"error,omitempty"`
+}
+
+type MealBatchResult struct {
+	Updated  int             `json:"updated"`
+	Rejected int             `json:"rejected"`
+	Results  []MealRowResult `json:"results"`
+}
+
+var legacyMealSlots = []string{"breakfast", "lunch", "dinner"}
+
+// Normalize folds the legacy fixed-slot fields into Meals (Meals wins when
+// both are given) and mirrors them back so older clients keep working.
+func (m *DailyMeal) Normalize() {
+	if m.Meals == nil {
+		m.Meals = make(map[string]float64)
+	}
+	legacy := []*float64{&m.Breakfast, &m.Lunch, &m.Dinner}
+	for i, key := range legacyMealSlots {
+		if _, ok := m.Meals[key]; !ok && *legacy[i] != 0 {
+			m.Meals[key] = *legacy[i]
+		}
+		*legacy[i] = m.Meals[key]
+	}
+	for key, count := range m.Meals {
+		if count == 0 {
+			delete(m.Meals, key)
+		}
+	}
 }
 
 // --- Month Lock ---
@@ -78,11 +279,149 @@ type MonthLock struct {
 	UnlockExpiry    time.Time `bson:"unlock_expiry,omitempty" json:"unlock_expiry,omitempty"`
 }
 
+// IsActive reports whether the lock blocks writes at the given time.
+// A temporary unlock re-locks automatically once UnlockExpiry has passed.
+func (l *MonthLock) IsActive(now time.Time) bool {
+	if l == nil {
+		return false
+	}
+	if l.IsLocked {
+		return true
+	}
+	return !l.UnlockExpiry.IsZero() && now.After(l.UnlockExpiry)
+}
+
+// --- Monthly Summary ---
+type MemberSummary struct {
+	UserID       string  `bson:"user_id" json:"user_id"`
+	Name         string  `bson:"name" json:"name"`
+	DaysPresent  int     `bson:"days_present" json:"days_present"` // Days in the mess this month; prorates service costs
+	TotalMeals   float64 `bson:"total_meals" json:"total_meals"`   // Weighted meal units
+	GuestMeals   int     `bson:"guest_meals" json:"guest_meals"`
+	GuestCharge  Money   `bson:"guest_charge" json:"guest_charge"` // Fixed-price guest meals, included in MealCost
+	MealCost     Money   `bson:"meal_cost" json:"meal_cost"`
+	ServiceShare Money   `bson:"service_share" json:"service_share"`
+	BazarSpent   Money   `bson:"bazar_spent" json:"bazar_spent"`     // Credit (Meals)
+	HousePaid    Money   `bson:"house_paid" json:"house_paid"`       // Credit (House)
+	MealPaid     Money   `bson:"meal_paid" json:"meal_paid"`         // Credit (Meals)
+	TotalPaid    Money   `bson:"total_paid" json:"total_paid"`       // Total Cash Payments
+	TotalDebit   Money   `bson:"total_debit" json:"total_debit"`     // ServiceShare + MealCost
+	TotalCredit  Money   `bson:"total_credit" json:"total_credit"`   // BazarSpent + HousePaid + MealPaid
+	HouseBalance Money   `bson:"house_balance" json:"house_balance"` // HousePaid - ServiceShare
+	MealBalance  Money   `bson:"meal_balance" json:"meal_balance"`   // BazarSpent + MealPaid - MealCost
+	Balance      Money   `bson:"balance" json:"balance"`             // TotalCredit - TotalDebit
+
+	OpeningBalance Money `bson:"opening_balance" json:"opening_balance"` // Carried from the previous closed month
+	ClosingBalance Money `bson:"closing_balance" json:"closing_balance"` // OpeningBalance + Balance
+}
+
+type MonthSummary struct {
+	Month            string                   `bson:"month" json:"month"`
+	TotalServiceCost Money                    `bson:"total_service_cost" json:"total_service_cost"`
+	TotalMealCost    Money                    `bson:"total_meal_cost" json:"total_meal_cost"`
+	MealRate         Money                    `bson:"meal_rate" json:"meal_rate"`
+	TotalMeals       float64                  `bson:"total_meals" json:"total_meals"`
+	MemberSummaries  map[string]MemberSummary `bson:"member_summaries" json:"member_summaries"`
+	SnapshotVersion  int                      `bson:"-" json:"snapshot_version,omitempty"` // Set when served from a frozen snapshot
+}
+
+// SummarySnapshot is the immutable summary stored when a month is closed.
+// Reopening and closing the month again stores a new version.
+type SummarySnapshot struct {
+	ID       string       `bson:"_id" json:"id"`
+	MessID   string       `bson:"mess_id" json:"mess_id"`
+	Month    string       `bson:"month" json:"month"`
+	Version  int          `bson:"version" json:"version"`
+	Summary  MonthSummary `bson:"summary" json:"summary"`
+	ClosedBy string       `bson:"closed_by" json:"closed_by"`
+	ClosedAt time.Time    `bson:"closed_at" json:"closed_at"`
+}
+
+// OpeningBalance carries a member's closing balances from SourceMonth into Month.
+// It is written when SourceMonth is closed and replaced if that month is re-closed.
+type OpeningBalance struct {
+	ID            string    `bson:"_id" json:"id"`
+	MessID        string    `bson:"mess_id" json:"mess_id"`
+	UserID        string    `bson:"user_id" json:"user_id"`
+	Month         string    `bson:"month" json:"month"`               // Month the balance opens
+	SourceMonth   string    `bson:"source_month" json:"source_month"` // Closed month it came from
+	SourceVersion int       `bson:"source_version" json:"source_version"`
+	HouseBalance  Money     `bson:"house_balance" json:"house_balance"`
+	MealBalance   Money     `bson:"meal_balance" json:"meal_balance"`
+	Balance       Money     `bson:"balance" json:"balance"` // HouseBalance + MealBalance
+	CreatedAt     time.Time `bson:"created_at" json:"created_at"`
+}
+
+// BalanceHistoryEntry is one closed month in a member's running balance.
+type BalanceHistoryEntry struct {
+	Month          string `json:"month"`
+	OpeningBalance Money  `json:"opening_balance"`
+	MonthBalance   Money  `json:"month_balance"`
+	ClosingBalance Money  `json:"closing_balance"`
+}
+
+// --- Settlement ---
+
+// MessFundID identifies the cash held by the manager in settlement transfers.
+const MessFundID = "MESS_FUND"
+
+// SettlementTransfer is one "who pays whom" step of a settlement plan.
+// Suggested transfers are computed on the fly; done transfers are stored.
+type SettlementTransfer struct {
+	ID          string    `bson:"_id" json:"id,omitempty"`
+	MessID      string    `bson:"mess_id" json:"mess_id"`
+	Month       string    `bson:"month" json:"month"`
+	FromID      string    `bson:"from_id" json:"from_id"`
+	FromName    string    `bson:"from_name" json:"from_name"`
+	ToID        string    `bson:"to_id" json:"to_id"`
+	ToName      string    `bson:"to_name" json:"to_name"`
+	Amount      Money     `bson:"amount" json:"amount"`
+	Status      string    `bson:"status" json:"status"` // suggested, done
+	PaymentIDs  []string  `bson:"payment_ids,omitempty" json:"payment_ids,omitempty"`
+	CompletedBy string    `bson:"completed_by,omitempty" json:"completed_by,omitempty"`
+	CompletedAt time.Time `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+}
+
+type SettlementPlan struct {
+	Month       string               `json:"month"`
+	Closed      bool                 `json:"closed"`       // Transfers can only be recorded once the month is closed
+	FundBalance Money                `json:"fund_balance"` // Cash held by the manager for the mess
+	Transfers   []SettlementTransfer `json:"transfers"`
+	Completed   []SettlementTransfer `json:"completed"`
+}
+
+// FinalSettlement is where a departed member stands in the month they left.
+// A positive Summary.ClosingBalance is owed to them, a negative one they owe.
+type FinalSettlement struct {
+	UserID  string        `json:"user_id"`
+	Name    string        `json:"name"`
+	LeftAt  time.Time     `json:"left_at"`
+	Month   string        `json:"month"`
+	Summary MemberSummary `json:"summary"`
+}
+
 type FinanceRepository interface {
 	// Service Costs
 	AddServiceCost(ctx context.Context, cost *ServiceCost) error
+	GetServiceCostByID(ctx context.Context, costID string) (*ServiceCost, error)
 	GetServiceCosts(ctx context.Context, messID, month string) ([]ServiceCost, error)
-	DeleteServiceCost(ctx context.Context, costID string) error
+	DeleteServiceCost(ctx context.Context, costID, deletedBy string, deletedAt time.Time) error
+	RestoreServiceCost(ctx context.Context, costID string) error
+	GetDeletedServiceCosts(ctx context.Context, messID string) ([]ServiceCost, error)
+	GetServiceCostsDeletedBefore(ctx context.Context, before time.Time) ([]ServiceCost, error)
+	PurgeServiceCost(ctx context.Context, costID string) error
+	AddServiceCostAttachment(ctx context.Context, costID, attachmentID string) error
+	UpdateServiceCost(ctx context.Context, cost *ServiceCost) error
+	GetServiceCostByTemplate(ctx context.Context, messID, templateID, month string) (*ServiceCost, error)
+
+	// Cost Templates
+	CreateCostTemplate(ctx context.Context, template *CostTemplate) error
+	GetCostTemplateByID(ctx context.Context, templateID string) (*CostTemplate, error)
+	GetCostTemplates(ctx context.Context, messID string) ([]CostTemplate, error)
+	GetCostTemplatesForMonth(ctx context.Context, month string) ([]CostTemplate, error)
+	UpdateCostTemplate(ctx context.Context, template *CostTemplate) error
+	DeleteCostTemplate(ctx context.Context, templateID string) error
+	SkipCostTemplateMonth(ctx context.Context, templateID, month string) error
 
 	// Payments
 	CreatePayment(ctx context.Context, payment *Payment) error
@@ -90,20 +429,56 @@ type FinanceRepository interface {
 	GetPayments(ctx context.Context, messID, month string) ([]Payment, error)
 	GetMemberPayments(ctx context.Context, messID, userID string) ([]Payment, error)
 	UpdatePaymentStatus(ctx context.Context, paymentID, status, approverID string) error
+	RejectPayment(ctx context.Context, paymentID, rejecterID, reason string) error
+	MarkPaymentReversed(ctx context.Context, paymentID, reversalID string) error
+	GetSettlingPayments(ctx context.Context, messID, month string) ([]Payment, error)
 
 	// Bazar
 	CreateBazar(ctx context.Context, bazar *Bazar) error
 	GetBazarByID(ctx context.Context, bazarID string) (*Bazar, error)
 	GetBazars(ctx context.Context, messID, month string) ([]Bazar, error)
+	GetBazarsInRange(ctx context.Context, messID, fromMonth, toMonth string) ([]Bazar, error)
 	ApproveBazar(ctx context.Context, bazarID string) error
 	UpdateBazar(ctx context.Context, bazar *Bazar) error
-	DeleteBazar(ctx context.Context, bazarID string) error
+	DeleteBazar(ctx context.Context, bazarID, deletedBy string, deletedAt time.Time) error
+	RestoreBazar(ctx context.Context, bazarID string) error
+	GetDeletedBazars(ctx context.Context, messID string) ([]Bazar, error)
+	GetBazarsDeletedBefore(ctx context.Context, before time.Time) ([]Bazar, error)
+	PurgeBazar(ctx context.Context, bazarID string) error
+	AddBazarAttachment(ctx context.Context, bazarID, attachmentID string) error
 
 	// Meals
 	UpsertDailyMeal(ctx context.Context, meal *DailyMeal) error
 	GetDailyMeals(ctx context.Context, messID, month string) ([]DailyMeal, error)
+	GetDailyMealsForDate(ctx context.Context, messID string, date time.Time) ([]DailyMeal, error)
+	InsertDailyMealIfMissing(ctx context.Context, meal *DailyMeal) (bool, error)
+
+	// Meal Schedules
+	UpsertMealSchedule(ctx context.Context, schedule *MealSchedule) error
+	GetMealSchedule(ctx context.Context, messID, userID string) (*MealSchedule, error)
+	GetMealSchedules(ctx context.Context, messID string) ([]MealSchedule, error)
+	GetActiveMealSchedules(ctx context.Context) ([]MealSchedule, error)
+	CreateMealOff(ctx context.Context, off *MealOff) error
+	GetMealOffByID(ctx context.Context, offID string) (*MealOff, error)
+	GetMealOffs(ctx context.Context, messID string, from, to time.Time) ([]MealOff, error)
+	DeleteMealOff(ctx context.Context, offID string) error
 
 	// Lock
 	GetMonthLock(ctx context.Context, messID, month string) (*MonthLock, error)
 	UpsertMonthLock(ctx context.Context, lock *MonthLock) error
+
+	// Summary Snapshots (append-only)
+	CreateSummarySnapshot(ctx context.Context, snapshot *SummarySnapshot) error
+	GetLatestSummarySnapshot(ctx context.Context, messID, month string) (*SummarySnapshot, error)
+	GetSummarySnapshots(ctx context.Context, messID, month string) ([]SummarySnapshot, error)
+	GetLastClosedMonth(ctx context.Context, messID string) (string, error)
+
+	// Opening Balances
+	UpsertOpeningBalance(ctx context.Context, balance *OpeningBalance) error
+	GetOpeningBalances(ctx context.Context, messID, month string) ([]OpeningBalance, error)
+	GetMemberOpeningBalances(ctx context.Context, messID, userID string) ([]OpeningBalance, error)
+
+	// Settlement
+	CreateSettlementTransfer(ctx context.Context, transfer *SettlementTransfer) error
+	GetSettlementTransfers(ctx context.Context, messID, month string) ([]SettlementTransfer, error)
 }
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestDailyMealNormalize(t *testing.T) {
+	tests := []struct {
+		name       string
+		meal       DailyMeal
+		want       map[string]float64
+		wantLegacy [3]float64
+	}{
+		{
+			name:       "legacy fields fold into meals",
+			meal:       DailyMeal{Breakfast: 0.5, Lunch: 1},
+			want:       map[string]float64{"breakfast": 0.5, "lunch": 1},
+			wantLegacy: [3]float64{0.5, 1, 0},
+		},
+		{
+			name:       "meals are mirrored to the legacy fields",
+			meal:       DailyMeal{Meals: map[string]float64{"lunch": 1, "dinner": 1, "iftar": 1}},
+			want:       map[string]float64{"lunch": 1, "dinner": 1, "iftar": 1},
+			wantLegacy: [3]float64{0, 1, 1},
+		},
+		{
+			name:       "meals win over legacy fields",
+			meal:       DailyMeal{Meals: map[string]float64{"lunch": 0.5}, Lunch: 1, Dinner: 1},
+			want:       map[string]float64{"lunch": 0.5, "dinner": 1},
+			wantLegacy: [3]float64{0, 0.5, 1},
+		},
+		{
+			name:       "zero counts are dropped",
+			meal:       DailyMeal{Meals: map[string]float64{"breakfast": 0, "lunch": 1}},
+			want:       map[string]float64{"lunch": 1},
+			wantLegacy: [3]float64{0, 1, 0},
+		},
+		{
+			name:       "nothing eaten",
+			meal:       DailyMeal{},
+			want:       map[string]float64{},
+			wantLegacy: [3]float64{0, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meal := tt.meal
+			meal.Normalize()
+			if !maps.Equal(meal.Meals, tt.want) {
+				t.Errorf("got meals %v, want %v", meal.Meals, tt.want)
+			}
+			if got := [3]float64{meal.Breakfast, meal.Lunch, meal.Dinner}; got != tt.wantLegacy {
+				t.Errorf("got breakfast/lunch/dinner %v, want %v", got, tt.wantLegacy)
+			}
+		})
+	}
+}
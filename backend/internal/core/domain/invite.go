@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Invite lets people join a mess without the approval step. Its ID is the
+// token shared as a link or QR code.
+type Invite struct {
+	ID        string     `bson:"_id" json:"id"`
+	MessID    string     `bson:"mess_id" json:"mess_id"`
+	Roles     []Role     `bson:"roles" json:"roles"` // Granted on joining, always including member
+	MaxUses   int        `bson:"max_uses" json:"max_uses"`
+	Uses      int        `bson:"uses" json:"uses"`
+	UsedBy    []string   `bson:"used_by" json:"used_by"`
+	ExpiresAt time.Time  `bson:"expires_at" json:"expires_at"`
+	RevokedAt *time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	CreatedBy string     `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time  `bson:"created_at" json:"created_at"`
+}
+
+// IsUsable reports whether the invite can still be redeemed.
+func (i *Invite) IsUsable(now time.Time) bool {
+	return i.RevokedAt == nil && now.Before(i.ExpiresAt) && i.Uses < i.MaxUses
+}
+
+// InvitePreview is what someone holding an invite sees before accepting it.
+type InvitePreview struct {
+	MessID    string    `json:"mess_id"`
+	MessName  string    `json:"mess_name"`
+	Roles     []Role    `json:"roles"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type InviteRepository interface {
+	Create(ctx context.Context, invite *Invite) error
+	GetByID(ctx context.Context, id string) (*Invite, error)
+	ListByMess(ctx context.Context, messID string) ([]Invite, error)
+	Revoke(ctx context.Context, id string, revokedAt time.Time) error
+	// Redeem records one use by the user, only if the invite is still usable
+	// and the user has not used it before. It reports whether it did.
+	Redeem(ctx context.Context, id, userID string, now time.Time) (bool, error)
+}
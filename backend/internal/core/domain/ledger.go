@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// --- Accounts ---
+type AccountType string
+
+const (
+	AccountTypeAsset   AccountType = "asset"   // Mess fund (cash held by the manager)
+	AccountTypeExpense AccountType = "expense" // Bazar and service cost categories
+	AccountTypeMember  AccountType = "member"  // What a member has paid minus what they were charged
+)
+
+// Account codes are derived, not stored: one fund per mess, one account per
+// member and one per expense category.
+const (
+	AccountFund           = "fund"
+	AccountExpenseBazar   = "expense:bazar"
+	AccountExpenseService = "expense:service"
+	memberAccountPrefix   = "member:"
+)
+
+func MemberAccount(userID string) string {
+	return memberAccountPrefix + userID
+}
+
+// AccountTypeOf classifies an account code.
+func AccountTypeOf(account string) AccountType {
+	switch {
+	case account == AccountFund:
+		return AccountTypeAsset
+	case strings.HasPrefix(account, memberAccountPrefix):
+		return AccountTypeMember
+	default:
+		return AccountTypeExpense
+	}
+}
+
+// MemberOfAccount returns the user ID of a member account, or "" for other accounts.
+func MemberOfAccount(account string) string {
+	return strings.TrimPrefix(account, memberAccountPrefix)
+}
+
+// --- Journal ---
+type JournalSource string
+
+const (
+	SourcePayment     JournalSource = "payment"
+	SourceBazar       JournalSource = "bazar"
+	SourceServiceCost JournalSource = "service_cost"
+	SourceAllocation  JournalSource = "allocation" // Month-end charge of expenses to members
+)
+
+type JournalLine struct {
+	Account string `bson:"account" json:"account"`
+	Debit   Money  `bson:"debit" json:"debit"`
+	Credit  Money  `bson:"credit" json:"credit"`
+}
+
+// JournalEntry is an append-only, balanced set of ledger lines. Corrections
+// are posted as reversing entries instead of editing or deleting.
+type JournalEntry struct {
+	ID          string        `bson:"_id" json:"id"`
+	MessID      string        `bson:"mess_id" json:"mess_id"`
+	Month       string        `bson:"month" json:"month"`
+	SourceType  JournalSource `bson:"source_type" json:"source_type"`
+	SourceID    string        `bson:"source_id" json:"source_id"`
+	Description string        `bson:"description" json:"description"`
+	Lines       []JournalLine `bson:"lines" json:"lines"`
+	ReversalOf  string        `bson:"reversal_of,omitempty" json:"reversal_of,omitempty"`
+	ReversedBy  string        `bson:"reversed_by,omitempty" json:"reversed_by,omitempty"`
+	CreatedBy   string        `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time     `bson:"created_at" json:"created_at"`
+}
+
+// --- Reports ---
+
+// AccountBalance is one row of a trial balance. Balance is on the account's
+// normal side: debit for assets and expenses, credit for members.
+type AccountBalance struct {
+	Account string      `json:"account"`
+	Type    AccountType `json:"type"`
+	UserID  string      `json:"user_id,omitempty"`
+	Debit   Money       `json:"debit"`
+	Credit  Money       `json:"credit"`
+	Balance Money       `json:"balance"`
+}
+
+type StatementLine struct {
+	EntryID        string        `json:"entry_id"`
+	Month          string        `json:"month"`
+	Date           time.Time     `json:"date"`
+	SourceType     JournalSource `json:"source_type"`
+	SourceID       string        `json:"source_id"`
+	Description    string        `json:"description"`
+	Debit          Money         `json:"debit"`
+	Credit         Money         `json:"credit"`
+	RunningBalance Money         `json:"running_balance"`
+}
+
+type AccountStatement struct {
+	Account string          `json:"account"`
+	Type    AccountType     `json:"type"`
+	Lines   []StatementLine `json:"lines"`
+	Balance Money           `json:"balance"`
+}
+
+type LedgerIntegrity struct {
+	Month             string   `json:"month"`
+	EntryCount        int      `json:"entry_count"`
+	TotalDebit        Money    `json:"total_debit"`
+	TotalCredit       Money    `json:"total_credit"`
+	Balanced          bool     `json:"balanced"`
+	UnbalancedEntries []string `json:"unbalanced_entries"`
+	// Fund movement in the ledger against the approved source documents
+	LedgerFundChange   Money `json:"ledger_fund_change"`
+	RecordsFundChange  Money `json:"records_fund_change"`
+	FundReconciliation bool  `json:"fund_reconciled"`
+}
+
+type LedgerRepository interface {
+	CreateEntry(ctx context.Context, entry *JournalEntry) error
+	GetEntries(ctx context.Context, messID, month string) ([]JournalEntry, error)
+	GetEntriesThrough(ctx context.Context, messID, month string) ([]JournalEntry, error)
+	GetEntriesBySource(ctx context.Context, messID string, sourceType JournalSource, sourceID string) ([]JournalEntry, error)
+	GetAccountEntries(ctx context.Context, messID, account string) ([]JournalEntry, error)
+	MarkEntryReversed(ctx context.Context, entryID, reversalID string) error
+}
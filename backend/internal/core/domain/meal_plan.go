@@ -0,0 +1,80 @@
+package domain
+
+import "time"
+
+// MealSchedule is a member's default meals, used to fill in their daily
+// meals automatically, e.g. {"lunch": 1, "dinner": 1} every day.
+type MealSchedule struct {
+	ID        string             `bson:"_id" json:"id"`
+	MessID    string             `bson:"mess_id" json:"mess_id"`
+	UserID    string             `bson:"user_id" json:"user_id"`
+	Meals     map[string]float64 `bson:"meals" json:"meals"`
+	Active    bool               `bson:"active" json:"active"`
+	UpdatedBy string             `bson:"updated_by" json:"updated_by"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// MealOff turns a member's scheduled meals off for a range of days, both
+// ends included. Without Slots every meal of those days is off.
+type MealOff struct {
+	ID        string    `bson:"_id" json:"id"`
+	MessID    string    `bson:"mess_id" json:"mess_id"`
+	UserID    string    `bson:"user_id" json:"user_id"`
+	From      time.Time `bson:"from" json:"from"`
+	To        time.Time `bson:"to" json:"to"`
+	Slots     []string  `bson:"slots,omitempty" json:"slots,omitempty"`
+	Reason    string    `bson:"reason,omitempty" json:"reason,omitempty"`
+	CreatedBy string    `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// Covers reports whether the meal-off turns off the slot on the date.
+func (o MealOff) Covers(date time.Time, slot string) bool {
+	if date.Before(o.From) || date.After(o.To) {
+		return false
+	}
+	if len(o.Slots) == 0 {
+		return true
+	}
+	for _, s := range o.Slots {
+		if s == slot {
+			return true
+		}
+	}
+	return false
+}
+
+// PlannedMeals applies meal-offs to a schedule for one day.
+func (s MealSchedule) PlannedMeals(date time.Time, offs []MealOff) map[string]float64 {
+	meals := make(map[string]float64)
+	for slot, count := range s.Meals {
+		off := false
+		for _, o := range offs {
+			if o.UserID == s.UserID && o.Covers(date, slot) {
+				off = true
+				break
+			}
+		}
+		if !off && count > 0 {
+			meals[slot] = count
+		}
+	}
+	return meals
+}
+
+// MealHeadcount is the number of meals to cook for one day.
+type MealHeadcount struct {
+	Date       time.Time            `json:"date"`
+	Slots      map[string]float64   `json:"slots"` // Slot key -> meals
+	GuestMeals int                  `json:"guest_meals"`
+	Members    []MemberMealsForDate `json:"members"`
+	Frozen     bool                 `json:"frozen"` // Past the cutoff; members can no longer change it
+}
+
+type MemberMealsForDate struct {
+	UserID     string             `json:"user_id"`
+	Name       string             `json:"name"`
+	Meals      map[string]float64 `json:"meals"`
+	GuestMeals int                `json:"guest_meals"`
+	Source     string             `json:"source"` // entered, schedule
+}
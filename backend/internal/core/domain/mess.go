@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"math"
 	"time"
 )
 
@@ -19,14 +20,240 @@ type Mess struct {
 	AdminID   string    `bson:"admin_id" json:"admin_id"`
 	Members   []Member  `bson:"members" json:"members"`
 	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+
+	MealConfig *MealConfig `bson:"meal_config,omitempty" json:"meal_config,omitempty"` // nil uses DefaultMealConfig
+
+	PendingTransfer *OwnershipTransfer `bson:"pending_transfer,omitempty" json:"pending_transfer,omitempty"`
+	DissolvedAt     *time.Time         `bson:"dissolved_at,omitempty" json:"dissolved_at,omitempty"` // Books are read-only once set
+}
+
+// OwnershipTransfer offers the mess to a member as its new owner (AdminID).
+// It waits until that member accepts or someone cancels it.
+type OwnershipTransfer struct {
+	ToUserID    string    `bson:"to_user_id" json:"to_user_id"`
+	RequestedBy string    `bson:"requested_by" json:"requested_by"`
+	RequestedAt time.Time `bson:"requested_at" json:"requested_at"`
+}
+
+// MealSlotsConfig returns the mess's meal configuration, or the default one.
+func (m *Mess) MealSlotsConfig() MealConfig {
+	if m.MealConfig == nil || len(m.MealConfig.Slots) == 0 {
+		return DefaultMealConfig()
+	}
+	return *m.MealConfig
+}
+
+// FindMember returns the member entry for the user, or nil.
+func (m *Mess) FindMember(userID string) *Member {
+	for i := range m.Members {
+		if m.Members[i].UserID == userID {
+			return &m.Members[i]
+		}
+	}
+	return nil
+}
+
+// IsDissolved reports whether the mess was dissolved.
+func (m *Mess) IsDissolved() bool {
+	return m.DissolvedAt != nil
+}
+
+// ActiveAdminOtherThan returns an active admin other than the user, or nil.
+// It is who takes over ownership when the owner goes.
+func (m *Mess) ActiveAdminOtherThan(userID string) *Member {
+	for i := range m.Members {
+		member := &m.Members[i]
+		if member.UserID != userID && member.Status == "active" && member.HasRole(RoleAdmin) {
+			return member
+		}
+	}
+	return nil
+}
+
+// MealSlot is one meal of the day and how many meal units it counts as.
+type MealSlot struct {
+	Key    string  `bson:"key" json:"key"` // e.g. breakfast
+	Name   string  `bson:"name" json:"name"`
+	Weight float64 `bson:"weight" json:"weight"`
+}
+
+// MealConfig decides how daily meals turn into meal units for the meal
+// rate. Guest meals count as GuestWeight units each, unless GuestPrice is
+// set, in which case each guest meal is charged that fixed price instead.
+type MealConfig struct {
+	Slots       []MealSlot `bson:"slots" json:"slots"`
+	GuestWeight float64    `bson:"guest_weight" json:"guest_weight"`
+	GuestPrice  Money      `bson:"guest_price" json:"guest_price"`
+	// CutoffTime ("HH:MM", server local time) after which members can no
+	// longer change tomorrow's meals. Empty means no cutoff.
+	CutoffTime string `bson:"cutoff_time,omitempty" json:"cutoff_time,omitempty"`
+}
+
+// DefaultMealConfig is three equal meals a day with guests counted as one meal.
+func DefaultMealConfig() MealConfig {
+	return MealConfig{
+		Slots: []MealSlot{
+			{Key: "breakfast", Name: "Breakfast", Weight: 1},
+			{Key: "lunch", Name: "Lunch", Weight: 1},
+			{Key: "dinner", Name: "Dinner", Weight: 1},
+		},
+		GuestWeight: 1,
+	}
+}
+
+func (c MealConfig) Slot(key string) (MealSlot, bool) {
+	for _, slot := range c.Slots {
+		if slot.Key == key {
+			return slot, true
+		}
+	}
+	return MealSlot{}, false
+}
+
+// Units converts a day's meals into weighted meal units. Slots that were
+// removed from the configuration still count at weight 1, so changing the
+// configuration never silently drops recorded meals.
+func (c MealConfig) Units(meal DailyMeal) float64 {
+	units := 0.0
+	for key, count := range meal.Meals {
+		weight := 1.0
+		if slot, ok := c.Slot(key); ok {
+			weight = slot.Weight
+		}
+		units += count * weight
+	}
+	if c.GuestPrice == 0 {
+		units += float64(meal.GuestMeals) * c.GuestWeight
+	}
+	return units
+}
+
+// FrozenThrough returns the last meal date members can no longer change:
+// tomorrow once today's cutoff has passed, otherwise today. It returns the
+// zero time when the mess has no cutoff.
+func (c MealConfig) FrozenThrough(now time.Time) time.Time {
+	if c.CutoffTime == "" {
+		return time.Time{}
+	}
+	cutoff, err := time.Parse("15:04", c.CutoffTime)
+	if err != nil {
+		return time.Time{}
+	}
+	now = now.In(time.Local)
+	today := MealDate(now)
+	todayCutoff := time.Date(now.Year(), now.Month(), now.Day(), cutoff.Hour(), cutoff.Minute(), 0, 0, time.Local)
+	if now.Before(todayCutoff) {
+		return today
+	}
+	return today.AddDate(0, 0, 1)
+}
+
+// MealDate is how daily meal dates are stored: midnight UTC of the local
+// calendar day, matching what clients send as a plain date.
+func MealDate(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// GuestCharge is the fixed-price charge for a day's guest meals, if any.
+func (c MealConfig) GuestCharge(meal DailyMeal) Money {
+	return c.GuestPrice * Money(meal.GuestMeals)
 }
 
 type Member struct {
-	UserID   string    `bson:"user_id" json:"user_id"`
-	Name     string    `bson:"name,omitempty" json:"name,omitempty"`
-	Roles    []Role    `bson:"roles" json:"roles"`
-	JoinedAt time.Time `bson:"joined_at" json:"joined_at"`
-	Status   string    `bson:"status" json:"status"` // active, pending, left
+	UserID   string     `bson:"user_id" json:"user_id"`
+	Name     string     `bson:"name,omitempty" json:"name,omitempty"`
+	Roles    []Role     `bson:"roles" json:"roles"`
+	JoinedAt time.Time  `bson:"joined_at" json:"joined_at"` // Request time while pending, reset when the member becomes active
+	LeftAt   *time.Time `bson:"left_at,omitempty" json:"left_at,omitempty"`
+	Status   string     `bson:"status" json:"status"` // active, pending, suspended, left
+
+	Suspensions []Suspension `bson:"suspensions,omitempty" json:"suspensions,omitempty"`
+}
+
+// Suspension is a stretch of time an admin kept a member out of meals and
+// shared costs. Until is nil while the suspension lasts.
+type Suspension struct {
+	From   time.Time  `bson:"from" json:"from"`
+	Until  *time.Time `bson:"until,omitempty" json:"until,omitempty"`
+	Reason string     `bson:"reason,omitempty" json:"reason,omitempty"`
+}
+
+// HasRole reports whether the member holds any of the given roles.
+func (m Member) HasRole(roles ...Role) bool {
+	for _, r := range m.Roles {
+		for _, want := range roles {
+			if r == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DaysPresent returns how many days of the month (YYYY-MM) the member
+// belonged to the mess, counting both the join and the leave day, along with
+// the number of days in the month. Pending members are never present, and
+// members who left before leave dates were recorded are treated as absent.
+// Days between a suspension and its end are not counted; the days it starts
+// and ends on are.
+func (m Member) DaysPresent(month string) (present, total int) {
+	start, err := time.ParseInLocation("2006-01", month, time.Local)
+	if err != nil {
+		return 0, 0
+	}
+	end := start.AddDate(0, 1, 0)
+	total = daysBetween(start, end)
+
+	from, to := start, end
+	switch m.Status {
+	case "active", "suspended":
+	case "left":
+		if m.LeftAt == nil {
+			return 0, total
+		}
+		if leaveEnd := startOfDay(*m.LeftAt).AddDate(0, 0, 1); leaveEnd.Before(to) {
+			to = leaveEnd
+		}
+	default:
+		return 0, total
+	}
+	if !m.JoinedAt.IsZero() {
+		if joined := startOfDay(m.JoinedAt); joined.After(from) {
+			from = joined
+		}
+	}
+
+	if !to.After(from) {
+		return 0, total
+	}
+	present = daysBetween(from, to)
+	for _, sus := range m.Suspensions {
+		absentFrom, absentTo := startOfDay(sus.From).AddDate(0, 0, 1), to
+		if sus.Until != nil {
+			absentTo = startOfDay(*sus.Until)
+		}
+		if absentFrom.Before(from) {
+			absentFrom = from
+		}
+		if absentTo.After(to) {
+			absentTo = to
+		}
+		if absentTo.After(absentFrom) {
+			present -= daysBetween(absentFrom, absentTo)
+		}
+	}
+	return present, total
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// daysBetween counts calendar days, rounding so DST shifts don't drop a day.
+func daysBetween(from, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
 }
 
 type MessRepository interface {
@@ -34,5 +261,10 @@ type MessRepository interface {
 	GetByID(ctx context.Context, id string) (*Mess, error)
 	Update(ctx context.Context, mess *Mess) error
 	AddMember(ctx context.Context, messID string, member Member) error
+	UpdateMealConfig(ctx context.Context, messID string, config MealConfig) error
+	// RemovePendingMember drops the user's entry only while it is still pending.
+	RemovePendingMember(ctx context.Context, messID, userID string) error
+	// GetWithPendingBefore returns messes holding join requests made before the cutoff.
+	GetWithPendingBefore(ctx context.Context, before time.Time) ([]Mess, error)
 	// More methods as needed
 }
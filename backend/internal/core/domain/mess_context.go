@@ -0,0 +1,21 @@
+package domain
+
+import "context"
+
+type messContextKey struct{}
+
+// WithMess stores the mess resolved for the current request so services can
+// check access without loading it again.
+func WithMess(ctx context.Context, mess *Mess) context.Context {
+	return context.WithValue(ctx, messContextKey{}, mess)
+}
+
+// MessFromContext returns the mess stored for the request if it is the one
+// with the given ID, or nil.
+func MessFromContext(ctx context.Context, messID string) *Mess {
+	mess, _ := ctx.Value(messContextKey{}).(*Mess)
+	if mess == nil || mess.ID != messID {
+		return nil
+	}
+	return mess
+}
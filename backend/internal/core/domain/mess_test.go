@@ -0,0 +1,216 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemberDaysPresent(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, time.June, d, 14, 30, 0, 0, time.Local)
+	}
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name   string
+		member Member
+		month  string
+		want   int
+	}{
+		{
+			name:   "active all month",
+			member: Member{Status: "active", JoinedAt: day(1).AddDate(0, -2, 0)},
+			month:  "2024-06",
+			want:   30,
+		},
+		{
+			name:   "joined mid month counts the join day",
+			member: Member{Status: "active", JoinedAt: day(21)},
+			month:  "2024-06",
+			want:   10,
+		},
+		{
+			name:   "left mid month counts the leave day",
+			member: Member{Status: "left", JoinedAt: day(1).AddDate(0, -1, 0), LeftAt: ptr(day(10))},
+			month:  "2024-06",
+			want:   10,
+		},
+		{
+			name:   "joined and left in the month",
+			member: Member{Status: "left", JoinedAt: day(5), LeftAt: ptr(day(5))},
+			month:  "2024-06",
+			want:   1,
+		},
+		{
+			name:   "left before the month",
+			member: Member{Status: "left", JoinedAt: day(1).AddDate(0, -3, 0), LeftAt: ptr(day(20).AddDate(0, -1, 0))},
+			month:  "2024-06",
+			want:   0,
+		},
+		{
+			name:   "joined after the month",
+			member: Member{Status: "active", JoinedAt: day(2).AddDate(0, 1, 0)},
+			month:  "2024-06",
+			want:   0,
+		},
+		{
+			name:   "left without a leave date",
+			member: Member{Status: "left", JoinedAt: day(1)},
+			month:  "2024-06",
+			want:   0,
+		},
+		{
+			name: "suspension keeps out the days between its start and end",
+			member: Member{Status: "active", JoinedAt: day(1).AddDate(0, -1, 0), Suspensions: []Suspension{
+				{From: day(10), Until: ptr(day(20))},
+			}},
+			month: "2024-06",
+			want:  21,
+		},
+		{
+			name: "running suspension lasts to the end of the month",
+			member: Member{Status: "suspended", JoinedAt: day(1).AddDate(0, -1, 0), Suspensions: []Suspension{
+				{From: day(25)},
+			}},
+			month: "2024-06",
+			want:  25,
+		},
+		{
+			name: "suspension from an earlier month",
+			member: Member{Status: "active", JoinedAt: day(1).AddDate(0, -2, 0), Suspensions: []Suspension{
+				{From: day(20).AddDate(0, -1, 0), Until: ptr(day(5))},
+			}},
+			month: "2024-06",
+			want:  26,
+		},
+		{
+			name: "removed while suspended",
+			member: Member{Status: "left", JoinedAt: day(1).AddDate(0, -1, 0), LeftAt: ptr(day(15)), Suspensions: []Suspension{
+				{From: day(10), Until: ptr(day(15))},
+			}},
+			month: "2024-06",
+			want:  11,
+		},
+		{
+			name:   "pending",
+			member: Member{Status: "pending", JoinedAt: day(1)},
+			month:  "2024-06",
+			want:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			present, total := tt.member.DaysPresent(tt.month)
+			if present != tt.want || total != 30 {
+				t.Errorf("DaysPresent(%s) = %d of %d, want %d of 30", tt.month, present, total, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemberDaysPresentInvalidMonth(t *testing.T) {
+	present, total := Member{Status: "active"}.DaysPresent("June")
+	if present != 0 || total != 0 {
+		t.Errorf("DaysPresent(June) = %d of %d, want 0 of 0", present, total)
+	}
+}
+
+func TestMealConfigUnits(t *testing.T) {
+	weighted := MealConfig{
+		Slots: []MealSlot{
+			{Key: "breakfast", Weight: 0.5},
+			{Key: "lunch", Weight: 1},
+			{Key: "dinner", Weight: 1},
+		},
+		GuestWeight: 1.5,
+	}
+	priced := weighted
+	priced.GuestPrice = Taka(80)
+
+	tests := []struct {
+		name   string
+		config MealConfig
+		meal   DailyMeal
+		want   float64
+	}{
+		{
+			name:   "default slots count one each",
+			config: DefaultMealConfig(),
+			meal:   DailyMeal{Meals: map[string]float64{"breakfast": 1, "lunch": 1, "dinner": 1}, GuestMeals: 1},
+			want:   4,
+		},
+		{
+			name:   "slot weights",
+			config: weighted,
+			meal:   DailyMeal{Meals: map[string]float64{"breakfast": 1, "lunch": 1}},
+			want:   1.5,
+		},
+		{
+			name:   "half meals are weighted too",
+			config: weighted,
+			meal:   DailyMeal{Meals: map[string]float64{"breakfast": 0.5, "dinner": 0.5}},
+			want:   0.75,
+		},
+		{
+			name:   "guests count at the guest weight",
+			config: weighted,
+			meal:   DailyMeal{Meals: map[string]float64{"lunch": 1}, GuestMeals: 2},
+			want:   4,
+		},
+		{
+			name:   "priced guests are not meal units",
+			config: priced,
+			meal:   DailyMeal{Meals: map[string]float64{"lunch": 1}, GuestMeals: 2},
+			want:   1,
+		},
+		{
+			name:   "removed slots still count at weight 1",
+			config: weighted,
+			meal:   DailyMeal{Meals: map[string]float64{"iftar": 2}},
+			want:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.Units(tt.meal); got != tt.want {
+				t.Errorf("Units(%v) = %v, want %v", tt.meal.Meals, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMealConfigGuestCharge(t *testing.T) {
+	tests := []struct {
+		name   string
+		config MealConfig
+		guests int
+		want   Money
+	}{
+		{name: "guests by weight are not charged", config: MealConfig{GuestWeight: 1}, guests: 3, want: 0},
+		{name: "fixed guest price", config: MealConfig{GuestWeight: 1, GuestPrice: Taka(80)}, guests: 3, want: Taka(240)},
+		{name: "no guests", config: MealConfig{GuestPrice: Taka(80)}, guests: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.GuestCharge(DailyMeal{GuestMeals: tt.guests}); got != tt.want {
+				t.Errorf("GuestCharge(%d guests) = %s, want %s", tt.guests, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessMealSlotsConfig(t *testing.T) {
+	if got := (&Mess{}).MealSlotsConfig(); len(got.Slots) != 3 || got.GuestWeight != 1 {
+		t.Errorf("got %+v for a mess without a configuration, want the default", got)
+	}
+	if got := (&Mess{MealConfig: &MealConfig{}}).MealSlotsConfig(); len(got.Slots) != 3 {
+		t.Errorf("got %+v for a configuration without slots, want the default", got)
+	}
+	config := &MealConfig{Slots: []MealSlot{{Key: "lunch", Weight: 1}}}
+	if got := (&Mess{MealConfig: config}).MealSlotsConfig(); len(got.Slots) != 1 {
+		t.Errorf("got %+v, want the mess's own configuration", got)
+	}
+}
@@ -0,0 +1,199 @@
+package domain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// Money is an amount in paisa (1/100 taka). It is stored as an integer in
+// MongoDB and travels as a decimal taka number in JSON, e.g. 1250.50.
+type Money int64
+
+// Taka converts whole taka to Money.
+func Taka(t int64) Money {
+	return Money(t * 100)
+}
+
+// ParseMoney reads a decimal taka amount such as "1250.5" without going
+// through float64. Digits beyond the paisa are rounded half away from zero.
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errors.New("empty amount")
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	for _, r := range whole + frac {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+	}
+
+	taka, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	roundUp := len(frac) > 2 && frac[2] >= '5'
+	frac = (frac + "00")[:2]
+	paisa, _ := strconv.ParseInt(frac, 10, 64)
+
+	m := Money(taka*100 + paisa)
+	if roundUp {
+		m++
+	}
+	if negative {
+		m = -m
+	}
+	return m, nil
+}
+
+// MoneyFromFloat converts a legacy float taka amount, rounding to the nearest paisa.
+func MoneyFromFloat(f float64) Money {
+	return Money(math.Round(f * 100))
+}
+
+// Float64 returns the amount in taka for ratios and display only; never
+// feed the result back into stored amounts.
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+func (m Money) String() string {
+	sign := ""
+	v := int64(m)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/100, v%100)
+}
+
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if string(data) == "null" || len(data) == 0 {
+		*m = 0
+		return nil
+	}
+	parsed, err := ParseMoney(string(data))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (m Money) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.MarshalValue(int64(m))
+}
+
+// UnmarshalBSONValue also accepts the float taka amounts written before
+// money moved to paisa, so unmigrated documents still read correctly.
+func (m *Money) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	value := bsoncore.Value{Type: t, Data: data}
+	switch t {
+	case bsontype.Int64:
+		*m = Money(value.Int64())
+	case bsontype.Int32:
+		*m = Money(value.Int32())
+	case bsontype.Double:
+		*m = MoneyFromFloat(value.Double())
+	case bsontype.Null, bsontype.Undefined:
+		*m = 0
+	default:
+		return fmt.Errorf("cannot decode %s into Money", t)
+	}
+	return nil
+}
+
+// Allocate splits m across the weights so that the parts always add up to m
+// exactly. Each part gets its proportional share rounded down to the paisa,
+// and the leftover paisa go one each to the largest remainders (ties to the
+// earliest weight). Zero total weight yields all-zero parts.
+func (m Money) Allocate(weights []float64) []Money {
+	parts := make([]Money, len(weights))
+	totalWeight := 0.0
+	for _, w := range weights {
+		if w > 0 {
+			totalWeight += w
+		}
+	}
+	if totalWeight == 0 || m == 0 {
+		return parts
+	}
+
+	total := m.Abs()
+	type remainder struct {
+		index int
+		frac  float64
+	}
+	remainders := make([]remainder, 0, len(weights))
+	allocated := Money(0)
+	for i, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		exact := float64(total) * w / totalWeight
+		part := Money(math.Floor(exact))
+		parts[i] = part
+		allocated += part
+		remainders = append(remainders, remainder{index: i, frac: exact - float64(part)})
+	}
+
+	sort.SliceStable(remainders, func(i, j int) bool { return remainders[i].frac > remainders[j].frac })
+	for i := 0; allocated < total; i++ {
+		parts[remainders[i%len(remainders)].index]++
+		allocated++
+	}
+
+	if m < 0 {
+		for i := range parts {
+			parts[i] = -parts[i]
+		}
+	}
+	return parts
+}
+
+// RatePer divides m by a quantity such as a meal count, rounding half away
+// from zero to the paisa. Used for display rates like the meal rate.
+func (m Money) RatePer(quantity float64) Money {
+	if quantity == 0 {
+		return 0
+	}
+	return Money(math.Round(float64(m) / quantity))
+}
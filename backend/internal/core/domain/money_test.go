@@ -0,0 +1,181 @@
+package domain
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		{in: "1250", want: 125000},
+		{in: "1250.5", want: 125050},
+		{in: "1250.50", want: 125050},
+		{in: ".75", want: 75},
+		{in: "-12.34", want: -1234},
+		{in: "0.005", want: 1},
+		{in: "0.004", want: 0},
+		{in: " 10 ", want: 1000},
+		{in: "", wantErr: true},
+		{in: ".", wantErr: true},
+		{in: "12a", wantErr: true},
+		{in: "1e3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseMoney(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMoney(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMoney(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	data, err := json.Marshal(struct{ Amount Money }{Amount: -125005})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"Amount":-1250.05}` {
+		t.Errorf("got %s", data)
+	}
+
+	var got struct{ Amount Money }
+	for in, want := range map[string]Money{
+		`{"Amount":1250.5}`:  125050,
+		`{"Amount":"99.99"}`: 9999,
+		`{"Amount":null}`:    0,
+	} {
+		if err := json.Unmarshal([]byte(in), &got); err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		if got.Amount != want {
+			t.Errorf("%s: got %s, want %s", in, got.Amount, want)
+		}
+	}
+}
+
+func TestMoneyBSONReadsLegacyFloats(t *testing.T) {
+	var doc struct {
+		Amount Money `bson:"amount"`
+	}
+	raw, err := bson.Marshal(bson.M{"amount": 1250.505})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Amount != 125051 {
+		t.Errorf("got %s from a legacy float, want 1250.51", doc.Amount)
+	}
+
+	raw, err = bson.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := bson.Raw(raw).Lookup("amount"); v.Type != bson.TypeInt64 || v.Int64() != 125051 {
+		t.Errorf("got %s, want the amount stored as int64 paisa", v)
+	}
+}
+
+func TestMoneyAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  Money
+		weights []float64
+		want    []Money
+	}{
+		{
+			name:    "even split",
+			amount:  Taka(300),
+			weights: []float64{1, 1, 1},
+			want:    []Money{10000, 10000, 10000},
+		},
+		{
+			name:    "leftover paisa go to the earliest on a tie",
+			amount:  100,
+			weights: []float64{1, 1, 1},
+			want:    []Money{34, 33, 33},
+		},
+		{
+			name:    "leftover paisa go to the largest remainders",
+			amount:  1000,
+			weights: []float64{1, 2, 4},
+			want:    []Money{143, 286, 571},
+		},
+		{
+			name:    "prorated by days present",
+			amount:  Taka(1000),
+			weights: []float64{30, 15, 0},
+			want:    []Money{66667, 33333, 0},
+		},
+		{
+			name:    "negative amounts split like positive ones",
+			amount:  -100,
+			weights: []float64{1, 1, 1},
+			want:    []Money{-34, -33, -33},
+		},
+		{
+			name:    "negative weights get nothing",
+			amount:  500,
+			weights: []float64{-1, 1},
+			want:    []Money{0, 500},
+		},
+		{
+			name:    "no weight",
+			amount:  500,
+			weights: []float64{0, 0},
+			want:    []Money{0, 0},
+		},
+		{
+			name:    "nothing to split",
+			amount:  0,
+			weights: []float64{1, 2},
+			want:    []Money{0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.amount.Allocate(tt.weights)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("%s.Allocate(%v) = %v, want %v", tt.amount, tt.weights, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyRatePer(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   Money
+		quantity float64
+		want     Money
+	}{
+		{name: "exact", amount: Taka(900), quantity: 20, want: 4500},
+		{name: "rounds down", amount: 1000, quantity: 3, want: 333},
+		{name: "rounds half away from zero", amount: 5, quantity: 2, want: 3},
+		{name: "negative rounds half away from zero", amount: -5, quantity: 2, want: -3},
+		{name: "fractional quantity", amount: Taka(100), quantity: 2.5, want: 4000},
+		{name: "no quantity", amount: Taka(100), quantity: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.amount.RatePer(tt.quantity); got != tt.want {
+				t.Errorf("%s.RatePer(%v) = %s, want %s", tt.amount, tt.quantity, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package domain
+
+import "context"
+
+// Transactor runs a unit of work atomically. Repository calls made with the
+// context passed to fn take part in the transaction; if fn returns an error
+// none of its writes are kept.
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type User struct {
 	ID            string   `bson:"_id" json:"id"` // e.g. MAHB-X972
@@ -13,6 +16,34 @@ type User struct {
 	CurrentMessID string   `bson:"current_mess_id,omitempty" json:"current_mess_id,omitempty"`
 	Messes        []string `bson:"messes" json:"messes"`
 	JoinRequests  []string `bson:"join_requests" json:"join_requests"`
+
+	JoinRejections []JoinRejection `bson:"join_rejections,omitempty" json:"join_rejections,omitempty"`
+}
+
+// JoinRejection tells a user why a join request of theirs was turned down,
+// either by an admin or because it expired.
+type JoinRejection struct {
+	MessID     string    `bson:"mess_id" json:"mess_id"`
+	MessName   string    `bson:"mess_name" json:"mess_name"`
+	Reason     string    `bson:"reason,omitempty" json:"reason,omitempty"`
+	Expired    bool      `bson:"expired,omitempty" json:"expired,omitempty"`
+	RejectedAt time.Time `bson:"rejected_at" json:"rejected_at"`
+}
+
+// Membership is one mess the user belongs to, as shown on their profile.
+type Membership struct {
+	MessID   string `json:"mess_id"`
+	MessName string `json:"mess_name"`
+	Roles    []Role `json:"roles"`
+	Status   string `json:"status"` // active or suspended
+	IsOwner  bool   `json:"is_owner"`
+	Current  bool   `json:"current"` // The mess the user has switched to
+}
+
+// UserProfile is the user together with all of their memberships.
+type UserProfile struct {
+	*User
+	Memberships []Membership `json:"memberships"`
 }
 
 type UserRepository interface {
@@ -21,4 +52,7 @@ type UserRepository interface {
 	GetByEmail(ctx context.Context, email string) (*User, error) // New
 	GetByPhone(ctx context.Context, phone string) (*User, error)
 	Update(ctx context.Context, user *User) error
+	AddMess(ctx context.Context, userID, messID string) error
+	SetCurrentMess(ctx context.Context, userID, messID string) error
+	SetCurrentMessIfUnset(ctx context.Context, userID, messID string) error
 }
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"context"
+	"fmt"
+)
+
+// accessLevel is the least a caller must hold in a mess for an operation.
+type accessLevel int
+
+const (
+	accessMember     accessLevel = iota // Any active member
+	accessManager                       // Manager or admin
+	accessAdmin                         // Admin only
+	accessReader                        // Any active or suspended member, and former ones once the mess is dissolved
+	accessBookkeeper                    // Manager or admin, and former ones once the mess is dissolved
+)
+
+func (l accessLevel) String() string {
+	switch l {
+	case accessManager, accessBookkeeper:
+		return "manager or admin"
+	case accessAdmin:
+		return "admin"
+	default:
+		return "members"
+	}
+}
+
+// authorize checks that the user holds at least the given level in the mess.
+// action completes the sentence "only <level> can ..." of the error.
+func authorize(ctx context.Context, messRepo domain.MessRepository, messID, userID string, level accessLevel, action string) error {
+	var ok bool
+	switch level {
+	case accessManager:
+		ok = isMessManager(ctx, messRepo, messID, userID)
+	case accessAdmin:
+		ok = isMessAdmin(ctx, messRepo, messID, userID)
+	case accessReader:
+		ok = readableMember(ctx, messRepo, messID, userID) != nil
+	case accessBookkeeper:
+		ok = isBookkeeper(ctx, messRepo, messID, userID)
+	default:
+		ok = isActiveMember(ctx, messRepo, messID, userID)
+	}
+	if !ok {
+		return fmt.Errorf("%w: only %s can %s", domain.ErrForbidden, level, action)
+	}
+	return nil
+}
+
+// notFound reports a missing entity. Entities that belong to a different mess
+// than the one addressed are reported the same way.
+func notFound(what string) error {
+	return fmt.Errorf("%s %w", what, domain.ErrNotFound)
+}
+
+// loadMess returns the mess resolved for this request by the membership
+// middleware, falling back to the repository.
+func loadMess(ctx context.Context, messRepo domain.MessRepository, messID string) (*domain.Mess, error) {
+	if mess := domain.MessFromContext(ctx, messID); mess != nil {
+		return mess, nil
+	}
+	return messRepo.GetByID(ctx, messID)
+}
+
+// activeMember returns the user's member entry if they are active in the mess.
+func activeMember(ctx context.Context, messRepo domain.MessRepository, messID, userID string) *domain.Member {
+	mess, err := loadMess(ctx, messRepo, messID)
+	if err != nil || mess == nil {
+		return nil
+	}
+	member := mess.FindMember(userID)
+	if member == nil || member.Status != "active" {
+		return nil
+	}
+	return member
+}
+
+// isMessManager reports whether the user is an active member holding the
+// manager or admin role in the mess.
+func isMessManager(ctx context.Context, messRepo domain.MessRepository, messID, userID string) bool {
+	member := activeMember(ctx, messRepo, messID, userID)
+	return member != nil && member.HasRole(domain.RoleManager, domain.RoleAdmin)
+}
+
+// isActiveMember reports whether the user is an active member of the mess.
+func isActiveMember(ctx context.Context, messRepo domain.MessRepository, messID, userID string) bool {
+	return activeMember(ctx, messRepo, messID, userID) != nil
+}
+
+// isMessAdmin reports whether the user holds the admin role in the mess.
+func isMessAdmin(ctx context.Context, messRepo domain.MessRepository, messID, userID string) bool {
+	member := activeMember(ctx, messRepo, messID, userID)
+	return member != nil && member.HasRole(domain.RoleAdmin)
+}
+
+// readableMember returns the user's member entry if they may read the books
+// of the mess: active and suspended members, and former members once it is
+// dissolved.
+func readableMember(ctx context.Context, messRepo domain.MessRepository, messID, userID string) *domain.Member {
+	mess, err := loadMess(ctx, messRepo, messID)
+	if err != nil || mess == nil {
+		return nil
+	}
+	member := mess.FindMember(userID)
+	if member == nil {
+		return nil
+	}
+	switch member.Status {
+	case "active", "suspended":
+		return member
+	case "left":
+		if mess.IsDissolved() {
+			return member
+		}
+	}
+	return nil
+}
+
+// isBookkeeper reports whether the user keeps the books of the mess: an
+// active manager or admin, or one who still held the role when the mess was
+// dissolved, e.g. to record the settlement.
+func isBookkeeper(ctx context.Context, messRepo domain.MessRepository, messID, userID string) bool {
+	mess, err := loadMess(ctx, messRepo, messID)
+	if err != nil || mess == nil {
+		return false
+	}
+	member := mess.FindMember(userID)
+	if member == nil || !member.HasRole(domain.RoleManager, domain.RoleAdmin) {
+		return false
+	}
+	if member.Status == "active" {
+		return true
+	}
+	return mess.IsDissolved() && member.Status == "left" && member.LeftAt != nil && !member.LeftAt.Before(*mess.DissolvedAt)
+}
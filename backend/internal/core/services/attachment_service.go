@@ -0,0 +1,287 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"amar-dera/pkg/utils"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // Registers GIF decoding for thumbnails
+	"image/jpeg"
+	_ "image/png" // Registers PNG decoding for thumbnails
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	thumbnailSize  = 320        // Longest edge in pixels
+	maxImagePixels = 40_000_000 // Width × height; larger images are refused before decoding
+)
+
+// allowedAttachmentTypes maps sniffed content types to stored file extensions.
+var allowedAttachmentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// AttachmentService stores receipt files for bazar entries and service costs.
+type AttachmentService struct {
+	repo        domain.AttachmentRepository
+	financeRepo domain.FinanceRepository
+	messRepo    domain.MessRepository
+	storage     domain.FileStorage
+	maxSize     int64
+	tx          domain.Transactor
+	audit       *AuditService
+}
+
+func NewAttachmentService(repo domain.AttachmentRepository, financeRepo domain.FinanceRepository, messRepo domain.MessRepository, storage domain.FileStorage, maxSize int64, tx domain.Transactor, audit *AuditService) *AttachmentService {
+	return &AttachmentService{repo: repo, financeRepo: financeRepo, messRepo: messRepo, storage: storage, maxSize: maxSize, tx: tx, audit: audit}
+}
+
+// MaxSize is the largest accepted upload in bytes.
+func (s *AttachmentService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// Upload attaches a file to a bazar entry or service cost of the mess.
+// Attaching evidence does not change any amount, so it is allowed on
+// locked months too.
+func (s *AttachmentService) Upload(ctx context.Context, messID string, entityType domain.AttachmentEntity, entityID, fileName string, data []byte, userID string) (*domain.Attachment, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: file is empty", domain.ErrInvalid)
+	}
+	if int64(len(data)) > s.maxSize {
+		return nil, fmt.Errorf("%w: file is larger than %d MB", domain.ErrInvalid, s.maxSize>>20)
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := allowedAttachmentTypes[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported file type %s", domain.ErrInvalid, contentType)
+	}
+	if strings.HasPrefix(contentType, "image/") {
+		if err := checkImageSize(data); err != nil {
+			return nil, err
+		}
+	}
+
+	month, err := s.checkUploadAccess(ctx, messID, entityType, entityID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := &domain.Attachment{
+		ID:          utils.GenerateID("ATT", 8),
+		MessID:      messID,
+		EntityType:  entityType,
+		EntityID:    entityID,
+		FileName:    filepath.Base(fileName),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		UploadedBy:  userID,
+		UploadedAt:  time.Now(),
+	}
+	attachment.StorageKey = fmt.Sprintf("%s/%s%s", messID, attachment.ID, ext)
+
+	if err := s.storage.Save(ctx, attachment.StorageKey, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	// Thumbnails are best effort; the original is what matters
+	if strings.HasPrefix(contentType, "image/") {
+		if thumb, err := makeThumbnail(data); err == nil {
+			key := fmt.Sprintf("%s/%s_thumb.jpg", messID, attachment.ID)
+			if err := s.storage.Save(ctx, key, bytes.NewReader(thumb)); err == nil {
+				attachment.ThumbnailKey = key
+				attachment.HasThumbnail = true
+			}
+		}
+	}
+
+	// The files are only kept if the attachment is fully recorded
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, attachment); err != nil {
+			return err
+		}
+		var err error
+		switch entityType {
+		case domain.AttachmentEntityBazar:
+			err = s.financeRepo.AddBazarAttachment(ctx, entityID, attachment.ID)
+		case domain.AttachmentEntityServiceCost:
+			err = s.financeRepo.AddServiceCostAttachment(ctx, entityID, attachment.ID)
+		}
+		if err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, messID, month, userID, domain.AuditCreate, domain.AuditEntityAttachment, attachment.ID, nil, attachment)
+	})
+	if err != nil {
+		s.removeFiles(ctx, attachment)
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// checkUploadAccess returns the month of the entity being attached to.
+func (s *AttachmentService) checkUploadAccess(ctx context.Context, messID string, entityType domain.AttachmentEntity, entityID, userID string) (string, error) {
+	switch entityType {
+	case domain.AttachmentEntityBazar:
+		bazar, err := s.financeRepo.GetBazarByID(ctx, entityID)
+		if err != nil || bazar == nil || bazar.MessID != messID || bazar.IsDeleted() {
+			return "", notFound("bazar entry")
+		}
+		if bazar.BuyerID == userID || bazar.CreatedBy == userID {
+			if isActiveMember(ctx, s.messRepo, messID, userID) {
+				return bazar.Month, nil
+			}
+		}
+		if !isMessManager(ctx, s.messRepo, messID, userID) {
+			return "", fmt.Errorf("%w: only the buyer or a manager can attach receipts to this bazar", domain.ErrForbidden)
+		}
+		return bazar.Month, nil
+	case domain.AttachmentEntityServiceCost:
+		cost, err := s.financeRepo.GetServiceCostByID(ctx, entityID)
+		if err != nil || cost == nil || cost.MessID != messID || cost.IsDeleted() {
+			return "", notFound("service cost")
+		}
+		if !isMessManager(ctx, s.messRepo, messID, userID) {
+			return "", fmt.Errorf("%w: only managers can attach receipts to service costs", domain.ErrForbidden)
+		}
+		return cost.Month, nil
+	default:
+		return "", fmt.Errorf("%w: invalid attachment target", domain.ErrInvalid)
+	}
+}
+
+// List returns the attachments of a bazar entry or service cost.
+func (s *AttachmentService) List(ctx context.Context, messID string, entityType domain.AttachmentEntity, entityID, userID string) ([]domain.Attachment, error) {
+	if err := authorize(ctx, s.messRepo, messID, userID, accessReader, "view attachments"); err != nil {
+		return nil, err
+	}
+	attachments, err := s.repo.ListByEntity(ctx, messID, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if attachments == nil {
+		attachments = []domain.Attachment{}
+	}
+	return attachments, nil
+}
+
+// Open returns an attachment and a reader for its file, or for its
+// thumbnail when thumb is set. Only members of the owning mess may read it.
+func (s *AttachmentService) Open(ctx context.Context, messID, attachmentID string, thumb bool, userID string) (*domain.Attachment, io.ReadCloser, error) {
+	if err := authorize(ctx, s.messRepo, messID, userID, accessReader, "view attachments"); err != nil {
+		return nil, nil, err
+	}
+
+	attachment, err := s.repo.GetByID(ctx, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if attachment == nil || attachment.MessID != messID {
+		return nil, nil, notFound("attachment")
+	}
+
+	key := attachment.StorageKey
+	if thumb {
+		if !attachment.HasThumbnail {
+			return nil, nil, errors.New("attachment has no thumbnail")
+		}
+		key = attachment.ThumbnailKey
+	}
+
+	file, err := s.storage.Open(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment, file, nil
+}
+
+// DeleteForEntity removes the attachments of a bazar entry or service cost
+// that is being purged, files included.
+func (s *AttachmentService) DeleteForEntity(ctx context.Context, messID string, entityType domain.AttachmentEntity, entityID string) error {
+	attachments, err := s.repo.ListByEntity(ctx, messID, entityType, entityID)
+	if err != nil {
+		return err
+	}
+	for i := range attachments {
+		if err := s.repo.Delete(ctx, attachments[i].ID); err != nil {
+			return err
+		}
+		s.removeFiles(ctx, &attachments[i])
+	}
+	return nil
+}
+
+func (s *AttachmentService) removeFiles(ctx context.Context, attachment *domain.Attachment) {
+	_ = s.storage.Delete(ctx, attachment.StorageKey)
+	if attachment.ThumbnailKey != "" {
+		_ = s.storage.Delete(ctx, attachment.ThumbnailKey)
+	}
+}
+
+// checkImageSize refuses images whose pixel count would take too much memory
+// to decode. Only the header is read. Formats that cannot be decoded here,
+// such as WebP, pass as they never get decoded.
+func checkImageSize(data []byte) error {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	if int64(config.Width)*int64(config.Height) > maxImagePixels {
+		return fmt.Errorf("%w: image is %dx%d pixels, more than %d megapixels", domain.ErrInvalid, config.Width, config.Height, maxImagePixels/1_000_000)
+	}
+	return nil
+}
+
+// makeThumbnail scales an image down so its longest edge is thumbnailSize
+// and encodes it as JPEG. Smaller images are re-encoded as they are.
+func makeThumbnail(data []byte) ([]byte, error) {
+	if err := checkImageSize(data); err != nil {
+		return nil, err
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w == 0 || h == 0 {
+		return nil, errors.New("empty image")
+	}
+	tw, th := w, h
+	if w > thumbnailSize || h > thumbnailSize {
+		if w >= h {
+			tw, th = thumbnailSize, max(1, h*thumbnailSize/w)
+		} else {
+			tw, th = max(1, w*thumbnailSize/h), thumbnailSize
+		}
+	}
+
+	// Nearest-neighbour sampling is plenty for a receipt preview
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		sy := bounds.Min.Y + y*h/th
+		for x := 0; x < tw; x++ {
+			sx := bounds.Min.X + x*w/tw
+			dst.Set(x, y, src.At(sx, sy))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
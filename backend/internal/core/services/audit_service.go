@@ -0,0 +1,79 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"amar-dera/pkg/utils"
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const (
+	auditSystemActor  = "system"
+	defaultAuditLimit = 200
+	maxAuditLimit     = 1000
+)
+
+// AuditService keeps the append-only log of changes to mess data.
+type AuditService struct {
+	repo     domain.AuditRepository
+	messRepo domain.MessRepository
+}
+
+func NewAuditService(repo domain.AuditRepository, messRepo domain.MessRepository) *AuditService {
+	return &AuditService{repo: repo, messRepo: messRepo}
+}
+
+// Record appends an entry for a change. before and after are the entity as
+// it was and as it is now; pass nil for the side that does not exist. Call
+// it inside the same transaction as the change so both land or neither does.
+func (s *AuditService) Record(ctx context.Context, messID, month, actorID string, action domain.AuditAction, entityType, entityID string, before, after interface{}) error {
+	entry := &domain.AuditEntry{
+		ID:         utils.GenerateID("AUD", 10),
+		MessID:     messID,
+		Month:      month,
+		ActorID:    actorID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		RequestID:  utils.RequestIDFromContext(ctx),
+		CreatedAt:  time.Now(),
+	}
+	var err error
+	if entry.Before, err = auditJSON(before); err != nil {
+		return err
+	}
+	if entry.After, err = auditJSON(after); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, entry)
+}
+
+func auditJSON(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// GetAuditLog returns the newest entries for the mess. Only mess admins can read it.
+func (s *AuditService) GetAuditLog(ctx context.Context, filter domain.AuditFilter, userID string) ([]domain.AuditEntry, error) {
+	if err := authorize(ctx, s.messRepo, filter.MessID, userID, accessAdmin, "view the audit log"); err != nil {
+		return nil, err
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+
+	entries, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []domain.AuditEntry{}
+	}
+	return entries, nil
+}
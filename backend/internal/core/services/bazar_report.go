@@ -0,0 +1,134 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// normalizeBazarItems validates structured line items and checks that they
+// add up to the bazar total. A missing total is taken from the items. Every
+// failure wraps domain.ErrInvalid.
+func normalizeBazarItems(bazar *domain.Bazar) error {
+	if len(bazar.LineItems) == 0 {
+		return nil
+	}
+
+	var sum domain.Money
+	for i := range bazar.LineItems {
+		item := &bazar.LineItems[i]
+		item.Name = strings.TrimSpace(item.Name)
+		item.Unit = strings.ToLower(strings.TrimSpace(item.Unit))
+		if item.Name == "" {
+			return fmt.Errorf("%w: item %d: name is required", domain.ErrInvalid, i+1)
+		}
+		if item.Quantity < 0 || item.UnitPrice < 0 || item.Total < 0 {
+			return fmt.Errorf("%w: item %q: quantity and prices cannot be negative", domain.ErrInvalid, item.Name)
+		}
+		if item.Category == "" {
+			item.Category = domain.BazarCategoryOther
+		}
+		if !item.Category.IsValid() {
+			return fmt.Errorf("%w: item %q: invalid category %q", domain.ErrInvalid, item.Name, item.Category)
+		}
+		if item.Total == 0 {
+			item.Total = domain.Money(math.Round(float64(item.UnitPrice) * item.Quantity))
+		}
+		sum += item.Total
+	}
+
+	if bazar.Amount == 0 {
+		bazar.Amount = sum
+	}
+	if sum != bazar.Amount {
+		return fmt.Errorf("%w: items add up to %s but the bazar amount is %s", domain.ErrInvalid, sum, bazar.Amount)
+	}
+	return nil
+}
+
+// GetBazarSpendReport totals approved bazar spend per item or per category
+// for every month in the range, plus totals across the range.
+func (s *FinanceService) GetBazarSpendReport(ctx context.Context, messID, fromMonth, toMonth, groupBy, userID string) (*domain.SpendReport, error) {
+	if err := s.authorize(ctx, messID, userID, accessReader, "view bazar reports"); err != nil {
+		return nil, err
+	}
+	if groupBy != "item" && groupBy != "category" {
+		return nil, errors.New("group_by must be item or category")
+	}
+	if fromMonth > toMonth {
+		return nil, errors.New("from month must not be after to month")
+	}
+
+	bazars, err := s.repo.GetBazarsInRange(ctx, messID, fromMonth, toMonth)
+	if err != nil {
+		return nil, err
+	}
+
+	type rowKey struct{ month, key, unit string }
+	rows := make(map[rowKey]*domain.SpendReportRow)
+	add := func(month, key, unit string, quantity float64, amount domain.Money) {
+		k := rowKey{month, key, unit}
+		row, ok := rows[k]
+		if !ok {
+			row = &domain.SpendReportRow{Month: month, Key: key, Unit: unit}
+			rows[k] = row
+		}
+		row.Quantity += quantity
+		row.Amount += amount
+	}
+
+	for _, b := range bazars {
+		if b.Status != "approved" {
+			continue
+		}
+		if len(b.LineItems) == 0 {
+			// Legacy free-text entries only have a total
+			add(b.Month, string(domain.BazarCategoryUncategorized), "", 0, b.Amount)
+			continue
+		}
+		for _, item := range b.LineItems {
+			if groupBy == "category" {
+				add(b.Month, string(item.Category), "", 0, item.Total)
+			} else {
+				add(b.Month, strings.ToLower(item.Name), item.Unit, item.Quantity, item.Total)
+			}
+		}
+	}
+
+	report := &domain.SpendReport{
+		GroupBy: groupBy,
+		From:    fromMonth,
+		To:      toMonth,
+		Rows:    []domain.SpendReportRow{},
+		Totals:  []domain.SpendReportRow{},
+	}
+	totals := make(map[rowKey]*domain.SpendReportRow)
+	for k, row := range rows {
+		report.Rows = append(report.Rows, *row)
+
+		tk := rowKey{"", k.key, k.unit}
+		total, ok := totals[tk]
+		if !ok {
+			total = &domain.SpendReportRow{Key: k.key, Unit: k.unit}
+			totals[tk] = total
+		}
+		total.Quantity += row.Quantity
+		total.Amount += row.Amount
+	}
+	for _, total := range totals {
+		report.Totals = append(report.Totals, *total)
+	}
+
+	sort.Slice(report.Rows, func(i, j int) bool {
+		if report.Rows[i].Month != report.Rows[j].Month {
+			return report.Rows[i].Month < report.Rows[j].Month
+		}
+		return report.Rows[i].Amount > report.Rows[j].Amount
+	})
+	sort.Slice(report.Totals, func(i, j int) bool { return report.Totals[i].Amount > report.Totals[j].Amount })
+	return report, nil
+}
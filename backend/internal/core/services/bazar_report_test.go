@@ -0,0 +1,237 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNormalizeBazarItems(t *testing.T) {
+	tests := []struct {
+		name       string
+		bazar      domain.Bazar
+		wantErr    bool
+		wantAmount domain.Money
+		wantItems  []domain.BazarItem
+	}{
+		{
+			name:       "no line items",
+			bazar:      domain.Bazar{Amount: domain.Taka(500), Items: "rice, dal"},
+			wantAmount: domain.Taka(500),
+		},
+		{
+			name: "totals are computed and the amount taken from them",
+			bazar: domain.Bazar{LineItems: []domain.BazarItem{
+				{Name: " Rice ", Quantity: 5, Unit: " KG ", UnitPrice: domain.Taka(70), Category: domain.BazarCategoryRice},
+				{Name: "Eggs", Quantity: 12, Unit: "pcs", UnitPrice: 1250},
+			}},
+			wantAmount: 50000,
+			wantItems: []domain.BazarItem{
+				{Name: "Rice", Quantity: 5, Unit: "kg", UnitPrice: domain.Taka(70), Total: domain.Taka(350), Category: domain.BazarCategoryRice},
+				{Name: "Eggs", Quantity: 12, Unit: "pcs", UnitPrice: 1250, Total: domain.Taka(150), Category: domain.BazarCategoryOther},
+			},
+		},
+		{
+			name: "given totals are kept",
+			bazar: domain.Bazar{Amount: domain.Taka(100), LineItems: []domain.BazarItem{
+				{Name: "Fish", Quantity: 1.5, UnitPrice: domain.Taka(80), Total: domain.Taka(100), Category: domain.BazarCategoryFish},
+			}},
+			wantAmount: domain.Taka(100),
+			wantItems: []domain.BazarItem{
+				{Name: "Fish", Quantity: 1.5, UnitPrice: domain.Taka(80), Total: domain.Taka(100), Category: domain.BazarCategoryFish},
+			},
+		},
+		{
+			name: "items must add up to the amount",
+			bazar: domain.Bazar{Amount: domain.Taka(400), LineItems: []domain.BazarItem{
+				{Name: "Rice", Quantity: 5, UnitPrice: domain.Taka(70)},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "name is required",
+			bazar: domain.Bazar{LineItems: []domain.BazarItem{
+				{Name: "  ", Quantity: 1, UnitPrice: 100},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "negative quantity",
+			bazar: domain.Bazar{LineItems: []domain.BazarItem{
+				{Name: "Oil", Quantity: -1, UnitPrice: 100},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "negative price",
+			bazar: domain.Bazar{LineItems: []domain.BazarItem{
+				{Name: "Oil", Quantity: 1, UnitPrice: -100},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "unknown category",
+			bazar: domain.Bazar{LineItems: []domain.BazarItem{
+				{Name: "Soap", Quantity: 1, UnitPrice: 100, Category: "toiletries"},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bazar := tt.bazar
+			err := normalizeBazarItems(&bazar)
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalid) {
+					t.Fatalf("got error %v, want one wrapping ErrInvalid", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if bazar.Amount != tt.wantAmount {
+				t.Errorf("got amount %s, want %s", bazar.Amount, tt.wantAmount)
+			}
+			if len(bazar.LineItems) != len(tt.wantItems) {
+				t.Fatalf("got %d items, want %d", len(bazar.LineItems), len(tt.wantItems))
+			}
+			for i, want := range tt.wantItems {
+				if got := bazar.LineItems[i]; got != want {
+					t.Errorf("item %d: got %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateBazarWithLineItems(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(member("a", domain.RoleManager), member("b"))
+	date := time.Date(2024, time.June, 10, 9, 0, 0, 0, time.Local)
+
+	short := domain.Bazar{MessID: env.messID, Date: date, Amount: domain.Taka(300), LineItems: []domain.BazarItem{
+		{Name: "Rice", Quantity: 5, UnitPrice: domain.Taka(70)},
+	}}
+	if err := env.finance.CreateBazar(ctx, short, "b"); !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("got %v for items not adding up, want ErrInvalid", err)
+	}
+	if len(env.repo.bazars) != 0 || len(env.journal.entries) != 0 {
+		t.Fatalf("a refused bazar recorded %d bazars and %d entries", len(env.repo.bazars), len(env.journal.entries))
+	}
+
+	bazar := domain.Bazar{MessID: env.messID, BuyerID: "b", Date: date, LineItems: []domain.BazarItem{
+		{Name: "Rice", Quantity: 5, UnitPrice: domain.Taka(70), Category: domain.BazarCategoryRice},
+		{Name: "Hilsa", Quantity: 1, UnitPrice: domain.Taka(650), Category: domain.BazarCategoryFish},
+	}}
+	if err := env.finance.CreateBazar(ctx, bazar, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if len(env.repo.bazars) != 1 {
+		t.Fatalf("got %d bazars, want 1", len(env.repo.bazars))
+	}
+	saved := env.repo.bazars[0]
+	if saved.Amount != domain.Taka(1000) || saved.Month != testMonth {
+		t.Errorf("got amount %s in %s, want 1000.00 in %s", saved.Amount, saved.Month, testMonth)
+	}
+	if len(env.journal.entries) != 1 || env.journal.entries[0].Lines[0].Debit != domain.Taka(1000) {
+		t.Errorf("got entries %+v, want one posting of 1000.00", env.journal.entries)
+	}
+}
+
+func TestUpdateBazarLineItems(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(member("a", domain.RoleManager), member("b"))
+	items := []domain.BazarItem{
+		{Name: "Rice", Quantity: 5, UnitPrice: domain.Taka(70), Total: domain.Taka(350), Category: domain.BazarCategoryRice},
+		{Name: "Dal", Quantity: 1, UnitPrice: domain.Taka(150), Total: domain.Taka(150), Category: domain.BazarCategoryOther},
+	}
+	env.repo.bazars = []domain.Bazar{
+		{ID: "BZ-1", MessID: env.messID, BuyerID: "b", Month: testMonth, Status: "pending", Amount: domain.Taka(500), Items: "rice, dal", LineItems: items},
+	}
+
+	// Left out of the request, the items are kept and still have to add up
+	if err := env.finance.UpdateBazar(ctx, domain.Bazar{ID: "BZ-1", MessID: env.messID, Amount: domain.Taka(600), Items: "rice, dal"}, "b"); !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("got %v for an amount the kept items do not add up to, want ErrInvalid", err)
+	}
+	if err := env.finance.UpdateBazar(ctx, domain.Bazar{ID: "BZ-1", MessID: env.messID, Amount: domain.Taka(500), Items: "rice and dal"}, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if got := env.repo.bazars[0]; len(got.LineItems) != 2 || got.Items != "rice and dal" {
+		t.Errorf("got %+v, want the two items kept", got)
+	}
+
+	// An empty list clears them
+	if err := env.finance.UpdateBazar(ctx, domain.Bazar{ID: "BZ-1", MessID: env.messID, Amount: domain.Taka(600), LineItems: []domain.BazarItem{}}, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if got := env.repo.bazars[0]; len(got.LineItems) != 0 || got.Amount != domain.Taka(600) {
+		t.Errorf("got %+v, want no items and 600.00", got)
+	}
+}
+
+func TestGetBazarSpendReport(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(member("a", domain.RoleManager), member("b"))
+	env.repo.bazars = []domain.Bazar{
+		{ID: "BZ-1", MessID: env.messID, Month: "2024-05", Status: "approved", Amount: domain.Taka(500), LineItems: []domain.BazarItem{
+			{Name: "Rice", Quantity: 5, Unit: "kg", Total: domain.Taka(350), Category: domain.BazarCategoryRice},
+			{Name: "Dal", Quantity: 2, Unit: "kg", Total: domain.Taka(150), Category: domain.BazarCategoryOther},
+		}},
+		{ID: "BZ-2", MessID: env.messID, Month: testMonth, Status: "approved", Amount: domain.Taka(360), LineItems: []domain.BazarItem{
+			{Name: "rice", Quantity: 5, Unit: "kg", Total: domain.Taka(360), Category: domain.BazarCategoryRice},
+		}},
+		{ID: "BZ-3", MessID: env.messID, Month: testMonth, Status: "approved", Amount: domain.Taka(80), Items: "tea"},
+		{ID: "BZ-4", MessID: env.messID, Month: testMonth, Status: "pending", Amount: domain.Taka(999), Items: "pending"},
+		{ID: "BZ-5", MessID: env.messID, Month: "2024-07", Status: "approved", Amount: domain.Taka(999), Items: "out of range"},
+	}
+
+	if _, err := env.finance.GetBazarSpendReport(ctx, env.messID, "2024-05", testMonth, "vendor", "a"); err == nil {
+		t.Error("expected an unknown group_by to be refused")
+	}
+	if _, err := env.finance.GetBazarSpendReport(ctx, env.messID, testMonth, "2024-05", "item", "a"); err == nil {
+		t.Error("expected a reversed range to be refused")
+	}
+	if _, err := env.finance.GetBazarSpendReport(ctx, env.messID, "2024-05", testMonth, "item", "stranger"); err == nil {
+		t.Error("expected non-members to be refused")
+	}
+
+	report, err := env.finance.GetBazarSpendReport(ctx, env.messID, "2024-05", testMonth, "item", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantTotals := []domain.SpendReportRow{
+		{Key: "rice", Unit: "kg", Quantity: 10, Amount: domain.Taka(710)},
+		{Key: "dal", Unit: "kg", Quantity: 2, Amount: domain.Taka(150)},
+		{Key: string(domain.BazarCategoryUncategorized), Amount: domain.Taka(80)},
+	}
+	checkSpendRows(t, report.Totals, wantTotals)
+	if len(report.Rows) != 4 {
+		t.Errorf("got %d monthly rows, want 4", len(report.Rows))
+	}
+
+	report, err = env.finance.GetBazarSpendReport(ctx, env.messID, "2024-05", testMonth, "category", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSpendRows(t, report.Totals, []domain.SpendReportRow{
+		{Key: string(domain.BazarCategoryRice), Amount: domain.Taka(710)},
+		{Key: string(domain.BazarCategoryOther), Amount: domain.Taka(150)},
+		{Key: string(domain.BazarCategoryUncategorized), Amount: domain.Taka(80)},
+	})
+}
+
+func checkSpendRows(t *testing.T, got, want []domain.SpendReportRow) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows %+v, want %d", len(got), got, len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("row %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"amar-dera/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+func validateMonth(month string) error {
+	if _, err := time.Parse("2006-01", month); err != nil {
+		return fmt.Errorf("%w: month must be in YYYY-MM format", domain.ErrInvalid)
+	}
+	return nil
+}
+
+func (s *FinanceService) validateCostTemplate(template *domain.CostTemplate) error {
+	template.Name = strings.TrimSpace(template.Name)
+	if template.Name == "" {
+		return errors.New("name is required")
+	}
+	if template.Amount <= 0 {
+		return errors.New("amount must be positive")
+	}
+	if err := validateCostShares(template.Amount, template.Shares); err != nil {
+		return err
+	}
+	if template.StartMonth == "" {
+		template.StartMonth = time.Now().Format("2006-01")
+	}
+	if err := validateMonth(template.StartMonth); err != nil {
+		return err
+	}
+	if template.EndMonth != "" {
+		if err := validateMonth(template.EndMonth); err != nil {
+			return err
+		}
+		if template.EndMonth < template.StartMonth {
+			return errors.New("end month must not be before start month")
+		}
+	}
+	return nil
+}
+
+func (s *FinanceService) CreateCostTemplate(ctx context.Context, template domain.CostTemplate, userID string) (*domain.CostTemplate, error) {
+	if err := s.authorize(ctx, template.MessID, userID, accessManager, "manage cost templates"); err != nil {
+		return nil, err
+	}
+	if err := s.validateCostTemplate(&template); err != nil {
+		return nil, err
+	}
+
+	template.ID = utils.GenerateID("TMPL", 4)
+	template.CreatedBy = userID
+	template.CreatedAt = time.Now()
+	template.UpdatedAt = template.CreatedAt
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateCostTemplate(ctx, &template); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, template.MessID, template.StartMonth, userID, domain.AuditCreate, domain.AuditEntityCostTemplate, template.ID, nil, template)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Fill the current month right away instead of waiting for the job
+	month := time.Now().Format("2006-01")
+	if template.AppliesTo(month) {
+		if _, err := s.materializeTemplate(ctx, &template, month, userID); err != nil {
+			log.Printf("Failed to apply cost template %s to %s: %v", template.ID, month, err)
+		}
+	}
+	return &template, nil
+}
+
+func (s *FinanceService) GetCostTemplates(ctx context.Context, messID, userID string) ([]domain.CostTemplate, error) {
+	if err := s.authorize(ctx, messID, userID, accessReader, "view cost templates"); err != nil {
+		return nil, err
+	}
+	templates, err := s.repo.GetCostTemplates(ctx, messID)
+	if err != nil {
+		return nil, err
+	}
+	if templates == nil {
+		templates = []domain.CostTemplate{}
+	}
+	return templates, nil
+}
+
+// UpdateCostTemplate changes a template for the months still to come. Costs
+// already copied into a month are edited through UpdateServiceCost.
+func (s *FinanceService) UpdateCostTemplate(ctx context.Context, update domain.CostTemplate, userID string) (*domain.CostTemplate, error) {
+	template, err := s.repo.GetCostTemplateByID(ctx, update.ID)
+	if err != nil || template == nil || template.MessID != update.MessID {
+		return nil, notFound("cost template")
+	}
+	if err := s.authorize(ctx, template.MessID, userID, accessManager, "manage cost templates"); err != nil {
+		return nil, err
+	}
+
+	before := *template
+	template.Name = update.Name
+	template.Amount = update.Amount
+	template.Shares = update.Shares
+	template.EndMonth = update.EndMonth
+	if update.StartMonth != "" {
+		template.StartMonth = update.StartMonth
+	}
+	if err := s.validateCostTemplate(template); err != nil {
+		return nil, err
+	}
+
+	template.UpdatedAt = time.Now()
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateCostTemplate(ctx, template); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, template.MessID, template.StartMonth, userID, domain.AuditUpdate, domain.AuditEntityCostTemplate, template.ID, before, template)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// DeleteCostTemplate stops a template from recurring. Costs already copied
+// into months are kept.
+func (s *FinanceService) DeleteCostTemplate(ctx context.Context, messID, templateID, userID string) error {
+	template, err := s.repo.GetCostTemplateByID(ctx, templateID)
+	if err != nil || template == nil || template.MessID != messID {
+		return notFound("cost template")
+	}
+	if err := s.authorize(ctx, messID, userID, accessManager, "manage cost templates"); err != nil {
+		return err
+	}
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.DeleteCostTemplate(ctx, templateID); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, messID, template.StartMonth, userID, domain.AuditDelete, domain.AuditEntityCostTemplate, template.ID, template, nil)
+	})
+}
+
+// ApplyCostTemplates copies the mess's templates into the month now rather
+// than waiting for the background job. Templates already applied are skipped.
+func (s *FinanceService) ApplyCostTemplates(ctx context.Context, messID, month, userID string) ([]domain.ServiceCost, error) {
+	if err := s.authorize(ctx, messID, userID, accessManager, "apply cost templates"); err != nil {
+		return nil, err
+	}
+	if err := validateMonth(month); err != nil {
+		return nil, err
+	}
+	if err := s.checkMonthLock(ctx, messID, month); err != nil {
+		return nil, err
+	}
+
+	templates, err := s.repo.GetCostTemplates(ctx, messID)
+	if err != nil {
+		return nil, err
+	}
+	created := []domain.ServiceCost{}
+	for i := range templates {
+		if !templates[i].AppliesTo(month) {
+			continue
+		}
+		cost, err := s.materializeTemplate(ctx, &templates[i], month, userID)
+		if err != nil {
+			return nil, err
+		}
+		if cost != nil {
+			created = append(created, *cost)
+		}
+	}
+	return created, nil
+}
+
+// MaterializeCostTemplates copies every mess's templates into the month.
+// Locked months and templates already applied are skipped.
+func (s *FinanceService) MaterializeCostTemplates(ctx context.Context, month string) error {
+	templates, err := s.repo.GetCostTemplatesForMonth(ctx, month)
+	if err != nil {
+		return err
+	}
+	for i := range templates {
+		// Dissolved messes take no new costs
+		if mess, err := s.messRepo.GetByID(ctx, templates[i].MessID); err == nil && mess != nil && mess.IsDissolved() {
+			continue
+		}
+		if _, err := s.materializeTemplate(ctx, &templates[i], month, auditSystemActor); err != nil && !errors.Is(err, domain.ErrMonthLocked) {
+			log.Printf("Failed to apply cost template %s to %s: %v", templates[i].ID, month, err)
+		}
+	}
+	return nil
+}
+
+// materializeTemplate creates the month's service cost for a template unless
+// it already exists or was purged from the month. Shares of anyone who is no
+// longer an active member are spread over the remaining shares.
+// actorID is who triggered it, recorded in the audit log.
+func (s *FinanceService) materializeTemplate(ctx context.Context, template *domain.CostTemplate, month, actorID string) (*domain.ServiceCost, error) {
+	existing, err := s.repo.GetServiceCostByTemplate(ctx, template.MessID, template.ID, month)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil || !template.AppliesTo(month) {
+		return nil, nil
+	}
+	if err := s.checkMonthLock(ctx, template.MessID, month); err != nil {
+		return nil, err
+	}
+
+	shares := redistributeShares(template.Amount, template.Shares, func(userID string) bool {
+		return s.isMember(ctx, template.MessID, userID)
+	})
+
+	cost := &domain.ServiceCost{
+		ID:         utils.GenerateID("COST", 4),
+		MessID:     template.MessID,
+		Month:      month,
+		Name:       template.Name,
+		Amount:     template.Amount,
+		Shares:     shares,
+		CreatedBy:  template.CreatedBy,
+		Status:     "approved",
+		TemplateID: template.ID,
+	}
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.AddServiceCost(ctx, cost); err != nil {
+			return err
+		}
+		if err := s.ledger.PostServiceCost(ctx, cost, template.CreatedBy); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, cost.MessID, month, actorID, domain.AuditCreate, domain.AuditEntityServiceCost, cost.ID, nil, cost)
+	})
+	// Applied concurrently by someone else
+	if errors.Is(err, domain.ErrDuplicate) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cost, nil
+}
+
+// redistributeShares drops the shares of users keep rejects and spreads the
+// total over the rest in proportion to what each already paid, so the split
+// still adds up to the total. If nobody is kept, it returns nil and the cost
+// is split the default way.
+func redistributeShares(total domain.Money, shares []domain.CostShare, keep func(userID string) bool) []domain.CostShare {
+	var kept []domain.CostShare
+	for _, share := range shares {
+		if keep(share.UserID) {
+			kept = append(kept, share)
+		}
+	}
+	if len(kept) == len(shares) {
+		return shares
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+
+	weights := make([]float64, len(kept))
+	var keptTotal domain.Money
+	for i, share := range kept {
+		weights[i] = float64(share.Amount)
+		keptTotal += share.Amount
+	}
+	// Only zero shares are left: split evenly among them
+	if keptTotal == 0 {
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+	for i, part := range total.Allocate(weights) {
+		kept[i].Amount = part
+	}
+	return kept
+}
+
+// StartCostTemplateScheduler starts a background goroutine that copies
+// recurring cost templates into the current month.
+func StartCostTemplateScheduler(financeService *FinanceService) {
+	go func() {
+		for {
+			month := time.Now().Format("2006-01")
+			if err := financeService.MaterializeCostTemplates(context.Background(), month); err != nil {
+				log.Printf("Failed to apply cost templates for %s: %v", month, err)
+			}
+
+			// Check again every few hours so a new month is filled early on the 1st
+			time.Sleep(6 * time.Hour)
+		}
+	}()
+}
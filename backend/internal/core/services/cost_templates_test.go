@@ -0,0 +1,67 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"slices"
+	"testing"
+)
+
+func TestRedistributeShares(t *testing.T) {
+	members := map[string]bool{"a": true, "b": true, "c": true}
+	keep := func(userID string) bool { return members[userID] }
+
+	tests := []struct {
+		name   string
+		total  domain.Money
+		shares []domain.CostShare
+		want   []domain.CostShare
+	}{
+		{
+			name:   "everyone still a member",
+			total:  domain.Taka(900),
+			shares: []domain.CostShare{{UserID: "a", Amount: domain.Taka(600)}, {UserID: "b", Amount: domain.Taka(300)}},
+			want:   []domain.CostShare{{UserID: "a", Amount: domain.Taka(600)}, {UserID: "b", Amount: domain.Taka(300)}},
+		},
+		{
+			name:  "departed share is spread in proportion",
+			total: domain.Taka(1000),
+			shares: []domain.CostShare{
+				{UserID: "a", Amount: domain.Taka(400)},
+				{UserID: "gone", Amount: domain.Taka(400)},
+				{UserID: "b", Amount: domain.Taka(200)},
+			},
+			want: []domain.CostShare{{UserID: "a", Amount: 66667}, {UserID: "b", Amount: 33333}},
+		},
+		{
+			name:   "remaining zero shares split evenly",
+			total:  domain.Taka(100),
+			shares: []domain.CostShare{{UserID: "gone", Amount: domain.Taka(100)}, {UserID: "a"}, {UserID: "b"}},
+			want:   []domain.CostShare{{UserID: "a", Amount: domain.Taka(50)}, {UserID: "b", Amount: domain.Taka(50)}},
+		},
+		{
+			name:   "nobody left falls back to the default split",
+			total:  domain.Taka(100),
+			shares: []domain.CostShare{{UserID: "gone", Amount: domain.Taka(100)}},
+			want:   nil,
+		},
+		{
+			name:  "no custom split",
+			total: domain.Taka(100),
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redistributeShares(tt.total, tt.shares, keep)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if got != nil {
+				if err := validateCostShares(tt.total, got); err != nil {
+					t.Errorf("shares no longer add up: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,383 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"context"
+	"slices"
+	"time"
+)
+
+// In-memory fakes of the repositories. Each embeds its interface, so a test
+// that reaches a method the fake does not implement panics instead of
+// passing silently.
+
+const testMonth = "2024-06"
+
+// testJoined is well before testMonth, so members count for all of it.
+var testJoined = time.Date(2024, time.January, 1, 12, 0, 0, 0, time.Local)
+
+func member(userID string, roles ...domain.Role) domain.Member {
+	return domain.Member{
+		UserID:   userID,
+		Name:     userID,
+		Roles:    append([]domain.Role{domain.RoleMember}, roles...),
+		JoinedAt: testJoined,
+		Status:   "active",
+	}
+}
+
+type fakeMessRepo struct {
+	domain.MessRepository
+	messes map[string]*domain.Mess
+}
+
+func (r *fakeMessRepo) GetByID(ctx context.Context, id string) (*domain.Mess, error) {
+	mess, ok := r.messes[id]
+	if !ok {
+		return nil, nil
+	}
+	clone := *mess
+	clone.Members = slices.Clone(mess.Members)
+	return &clone, nil
+}
+
+func (r *fakeMessRepo) Update(ctx context.Context, mess *domain.Mess) error {
+	clone := *mess
+	clone.Members = slices.Clone(mess.Members)
+	r.messes[mess.ID] = &clone
+	return nil
+}
+
+type fakeUserRepo struct {
+	domain.UserRepository
+	users map[string]*domain.User
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, nil
+	}
+	clone := *user
+	return &clone, nil
+}
+
+func (r *fakeUserRepo) Update(ctx context.Context, user *domain.User) error {
+	clone := *user
+	r.users[user.ID] = &clone
+	return nil
+}
+
+func (r *fakeUserRepo) SetCurrentMess(ctx context.Context, userID, messID string) error {
+	if user, ok := r.users[userID]; ok {
+		user.CurrentMessID = messID
+	}
+	return nil
+}
+
+type fakeFinanceRepo struct {
+	domain.FinanceRepository
+	costs     []domain.ServiceCost
+	payments  []domain.Payment
+	bazars    []domain.Bazar
+	meals     []domain.DailyMeal
+	locks     []domain.MonthLock
+	openings  []domain.OpeningBalance
+	snapshots []domain.SummarySnapshot
+	transfers []domain.SettlementTransfer
+}
+
+func (r *fakeFinanceRepo) AddServiceCost(ctx context.Context, cost *domain.ServiceCost) error {
+	r.costs = append(r.costs, *cost)
+	return nil
+}
+
+func (r *fakeFinanceRepo) GetServiceCosts(ctx context.Context, messID, month string) ([]domain.ServiceCost, error) {
+	var costs []domain.ServiceCost
+	for _, c := range r.costs {
+		if c.MessID == messID && c.Month == month {
+			costs = append(costs, c)
+		}
+	}
+	return costs, nil
+}
+
+func (r *fakeFinanceRepo) CreatePayment(ctx context.Context, payment *domain.Payment) error {
+	r.payments = append(r.payments, *payment)
+	return nil
+}
+
+func (r *fakeFinanceRepo) GetPayments(ctx context.Context, messID, month string) ([]domain.Payment, error) {
+	var payments []domain.Payment
+	for _, p := range r.payments {
+		if p.MessID == messID && p.Month == month {
+			payments = append(payments, p)
+		}
+	}
+	return payments, nil
+}
+
+func (r *fakeFinanceRepo) CreateBazar(ctx context.Context, bazar *domain.Bazar) error {
+	r.bazars = append(r.bazars, *bazar)
+	return nil
+}
+
+func (r *fakeFinanceRepo) GetBazarByID(ctx context.Context, id string) (*domain.Bazar, error) {
+	for _, b := range r.bazars {
+		if b.ID == id {
+			return &b, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeFinanceRepo) UpdateBazar(ctx context.Context, bazar *domain.Bazar) error {
+	for i, b := range r.bazars {
+		if b.ID == bazar.ID {
+			r.bazars[i] = *bazar
+		}
+	}
+	return nil
+}
+
+func (r *fakeFinanceRepo) GetBazarsInRange(ctx context.Context, messID, fromMonth, toMonth string) ([]domain.Bazar, error) {
+	var bazars []domain.Bazar
+	for _, b := range r.bazars {
+		if b.MessID == messID && b.Month >= fromMonth && b.Month <= toMonth {
+			bazars = append(bazars, b)
+		}
+	}
+	return bazars, nil
+}
+
+func (r *fakeFinanceRepo) GetSettlingPayments(ctx context.Context, messID, month string) ([]domain.Payment, error) {
+	var payments []domain.Payment
+	for _, p := range r.payments {
+		if p.MessID == messID && p.SettlesMonth == month {
+			payments = append(payments, p)
+		}
+	}
+	return payments, nil
+}
+
+func (r *fakeFinanceRepo) GetBazars(ctx context.Context, messID, month string) ([]domain.Bazar, error) {
+	var bazars []domain.Bazar
+	for _, b := range r.bazars {
+		if b.MessID == messID && b.Month == month {
+			bazars = append(bazars, b)
+		}
+	}
+	return bazars, nil
+}
+
+func (r *fakeFinanceRepo) UpsertDailyMeal(ctx context.Context, meal *domain.DailyMeal) error {
+	for i, m := range r.meals {
+		if m.MessID == meal.MessID && m.UserID == meal.UserID && m.Date.Equal(meal.Date) {
+			r.meals[i] = *meal
+			return nil
+		}
+	}
+	r.meals = append(r.meals, *meal)
+	return nil
+}
+
+func (r *fakeFinanceRepo) GetDailyMeals(ctx context.Context, messID, month string) ([]domain.DailyMeal, error) {
+	var meals []domain.DailyMeal
+	for _, m := range r.meals {
+		if m.MessID == messID && m.Month == month {
+			meals = append(meals, m)
+		}
+	}
+	return meals, nil
+}
+
+func (r *fakeFinanceRepo) GetDailyMealsForDate(ctx context.Context, messID string, date time.Time) ([]domain.DailyMeal, error) {
+	var meals []domain.DailyMeal
+	for _, m := range r.meals {
+		if m.MessID == messID && m.Date.Equal(date) {
+			meals = append(meals, m)
+		}
+	}
+	return meals, nil
+}
+
+func (r *fakeFinanceRepo) GetMonthLock(ctx context.Context, messID, month string) (*domain.MonthLock, error) {
+	for _, l := range r.locks {
+		if l.MessID == messID && l.Month == month {
+			return &l, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeFinanceRepo) UpsertMonthLock(ctx context.Context, lock *domain.MonthLock) error {
+	for i, l := range r.locks {
+		if l.MessID == lock.MessID && l.Month == lock.Month {
+			r.locks[i] = *lock
+			return nil
+		}
+	}
+	r.locks = append(r.locks, *lock)
+	return nil
+}
+
+func (r *fakeFinanceRepo) CreateSummarySnapshot(ctx context.Context, snapshot *domain.SummarySnapshot) error {
+	r.snapshots = append(r.snapshots, *snapshot)
+	return nil
+}
+
+func (r *fakeFinanceRepo) GetLatestSummarySnapshot(ctx context.Context, messID, month string) (*domain.SummarySnapshot, error) {
+	var latest *domain.SummarySnapshot
+	for i, s := range r.snapshots {
+		if s.MessID == messID && s.Month == month && (latest == nil || s.Version > latest.Version) {
+			latest = &r.snapshots[i]
+		}
+	}
+	return latest, nil
+}
+
+func (r *fakeFinanceRepo) GetLastClosedMonth(ctx context.Context, messID string) (string, error) {
+	last := ""
+	for _, s := range r.snapshots {
+		if s.MessID == messID && s.Month > last {
+			last = s.Month
+		}
+	}
+	return last, nil
+}
+
+func (r *fakeFinanceRepo) UpsertOpeningBalance(ctx context.Context, balance *domain.OpeningBalance) error {
+	for i, o := range r.openings {
+		if o.MessID == balance.MessID && o.UserID == balance.UserID && o.Month == balance.Month {
+			r.openings[i] = *balance
+			return nil
+		}
+	}
+	r.openings = append(r.openings, *balance)
+	return nil
+}
+
+func (r *fakeFinanceRepo) GetOpeningBalances(ctx context.Context, messID, month string) ([]domain.OpeningBalance, error) {
+	var openings []domain.OpeningBalance
+	for _, o := range r.openings {
+		if o.MessID == messID && o.Month == month {
+			openings = append(openings, o)
+		}
+	}
+	return openings, nil
+}
+
+func (r *fakeFinanceRepo) CreateSettlementTransfer(ctx context.Context, transfer *domain.SettlementTransfer) error {
+	r.transfers = append(r.transfers, *transfer)
+	return nil
+}
+
+func (r *fakeFinanceRepo) GetSettlementTransfers(ctx context.Context, messID, month string) ([]domain.SettlementTransfer, error) {
+	var transfers []domain.SettlementTransfer
+	for _, t := range r.transfers {
+		if t.MessID == messID && t.Month == month {
+			transfers = append(transfers, t)
+		}
+	}
+	return transfers, nil
+}
+
+type fakeLedgerRepo struct {
+	domain.LedgerRepository
+	entries []domain.JournalEntry
+}
+
+func (r *fakeLedgerRepo) CreateEntry(ctx context.Context, entry *domain.JournalEntry) error {
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+func (r *fakeLedgerRepo) GetEntries(ctx context.Context, messID, month string) ([]domain.JournalEntry, error) {
+	var entries []domain.JournalEntry
+	for _, e := range r.entries {
+		if e.MessID == messID && e.Month == month {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func (r *fakeLedgerRepo) GetEntriesThrough(ctx context.Context, messID, month string) ([]domain.JournalEntry, error) {
+	var entries []domain.JournalEntry
+	for _, e := range r.entries {
+		if e.MessID == messID && e.Month <= month {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func (r *fakeLedgerRepo) GetEntriesBySource(ctx context.Context, messID string, sourceType domain.JournalSource, sourceID string) ([]domain.JournalEntry, error) {
+	var entries []domain.JournalEntry
+	for _, e := range r.entries {
+		if e.MessID == messID && e.SourceType == sourceType && e.SourceID == sourceID {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func (r *fakeLedgerRepo) MarkEntryReversed(ctx context.Context, entryID, reversalID string) error {
+	for i := range r.entries {
+		if r.entries[i].ID == entryID {
+			r.entries[i].ReversedBy = reversalID
+		}
+	}
+	return nil
+}
+
+type fakeAuditRepo struct {
+	domain.AuditRepository
+	entries []domain.AuditEntry
+}
+
+func (r *fakeAuditRepo) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+// fakeTransactor runs the unit of work directly; the fakes have nothing to
+// roll back, so tests check that refused writes fail before writing.
+type fakeTransactor struct{}
+
+func (fakeTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// testEnv wires the services to fakes holding a single mess.
+type testEnv struct {
+	messID  string
+	messes  *fakeMessRepo
+	users   *fakeUserRepo
+	repo    *fakeFinanceRepo
+	journal *fakeLedgerRepo
+	audit   *fakeAuditRepo
+	ledger  *LedgerService
+	finance *FinanceService
+	mess    *MessService
+}
+
+func newTestEnv(members ...domain.Member) *testEnv {
+	env := &testEnv{
+		messID:  "MESS-1",
+		messes:  &fakeMessRepo{messes: make(map[string]*domain.Mess)},
+		users:   &fakeUserRepo{users: make(map[string]*domain.User)},
+		repo:    &fakeFinanceRepo{},
+		journal: &fakeLedgerRepo{},
+		audit:   &fakeAuditRepo{},
+	}
+	env.messes.messes[env.messID] = &domain.Mess{ID: env.messID, Name: "Test Mess", Members: members, CreatedAt: testJoined}
+	for _, m := range members {
+		env.users.users[m.UserID] = &domain.User{ID: m.UserID, Name: m.Name, Messes: []string{env.messID}, CurrentMessID: env.messID}
+	}
+	audit := NewAuditService(env.audit, env.messes)
+	env.ledger = NewLedgerService(env.journal, env.repo, env.messes)
+	env.finance = NewFinanceService(env.repo, env.messes, env.users, env.ledger, fakeTransactor{}, audit)
+	env.mess = NewMessService(env.messes, env.users, nil, env.finance, fakeTransactor{}, audit)
+	return env
+}
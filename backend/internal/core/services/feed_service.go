@@ -30,15 +30,16 @@ func (s *FeedService) CreatePost(ctx context.Context, post *domain.FeedPost) (*d
 	}
 	post.UserName = user.Name
 
-	// If messID is not provided (from handler/request), use user's current mess
-	if post.MessID == "" {
-		post.MessID = user.CurrentMessID
-	}
-
-	if post.MessID != "" {
-		mess, err := s.messRepo.GetByID(ctx, post.MessID)
+	// Posts are tagged with the mess the user has switched to, as long as
+	// they are still an active member of it
+	post.MessID = ""
+	if user.CurrentMessID != "" {
+		mess, err := s.messRepo.GetByID(ctx, user.CurrentMessID)
 		if err == nil && mess != nil {
-			post.MessName = mess.Name
+			if member := mess.FindMember(user.ID); member != nil && member.Status == "active" {
+				post.MessID = mess.ID
+				post.MessName = mess.Name
+			}
 		}
 	}
 
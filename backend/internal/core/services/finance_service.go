@@ -5,6 +5,8 @@ import (
 	"amar-dera/pkg/utils"
 	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"time"
 )
 
@@ -12,16 +14,19 @@ type FinanceService struct {
 	repo     domain.FinanceRepository
 	messRepo domain.MessRepository
 	userRepo domain.UserRepository
+	ledger   *LedgerService
+	tx       domain.Transactor
+	audit    *AuditService
 }
 
-func NewFinanceService(repo domain.FinanceRepository, messRepo domain.MessRepository, userRepo domain.UserRepository) *FinanceService {
-	return &FinanceService{repo: repo, messRepo: messRepo, userRepo: userRepo}
+func NewFinanceService(repo domain.FinanceRepository, messRepo domain.MessRepository, userRepo domain.UserRepository, ledger *LedgerService, tx domain.Transactor, audit *AuditService) *FinanceService {
+	return &FinanceService{repo: repo, messRepo: messRepo, userRepo: userRepo, ledger: ledger, tx: tx, audit: audit}
 }
 
 func (s *FinanceService) AddServiceCost(ctx context.Context, cost domain.ServiceCost, userID string) error {
 	// Check if user is manager or admin
-	if !s.isManager(ctx, cost.MessID, userID) {
-		return errors.New("only manager or admin can add service costs")
+	if err := s.authorize(ctx, cost.MessID, userID, accessManager, "add service costs"); err != nil {
+		return err
 	}
 
 	if cost.ID == "" {
@@ -30,69 +35,381 @@ func (s *FinanceService) AddServiceCost(ctx context.Context, cost domain.Service
 	cost.CreatedBy = userID
 	cost.Status = "approved"
 
-	// Validate Shares if present
-	if len(cost.Shares) > 0 {
-		totalShares := 0.0
-		for _, s := range cost.Shares {
-			totalShares += s.Amount
-		}
-		// Allow small float tolerance if needed, but for currency exact match is preferred usually.
-		// Let's use a small epsilon for float comparison.
-		diff := cost.Amount - totalShares
-		if diff < -0.1 || diff > 0.1 {
-			return errors.New("sum of shares must equal total amount")
-		}
+	if err := validateCostShares(cost.Amount, cost.Shares); err != nil {
+		return err
 	}
 
-	// TODO: Check Month Lock
-	return s.repo.AddServiceCost(ctx, &cost)
+	if err := s.checkMonthLock(ctx, cost.MessID, cost.Month); err != nil {
+		return err
+	}
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.AddServiceCost(ctx, &cost); err != nil {
+			return err
+		}
+		if err := s.ledger.PostServiceCost(ctx, &cost, userID); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, cost.MessID, cost.Month, userID, domain.AuditCreate, domain.AuditEntityServiceCost, cost.ID, nil, cost)
+	})
 }
 
 func (s *FinanceService) GetServiceCosts(ctx context.Context, messID, month string) ([]domain.ServiceCost, error) {
 	return s.repo.GetServiceCosts(ctx, messID, month)
 }
 
-func (s *FinanceService) DeleteServiceCost(ctx context.Context, costID string) error {
-	// TODO: Check Month Lock
-	return s.repo.DeleteServiceCost(ctx, costID)
+// validateCostShares checks that a custom split adds up to the amount.
+func validateCostShares(amount domain.Money, shares []domain.CostShare) error {
+	if len(shares) == 0 {
+		return nil
+	}
+	var totalShares domain.Money
+	for _, s := range shares {
+		totalShares += s.Amount
+	}
+	// Amounts are exact paisa, so shares must add up to the paisa
+	if totalShares != amount {
+		return fmt.Errorf("%w: sum of shares must equal total amount", domain.ErrInvalid)
+	}
+	return nil
 }
 
-func (s *FinanceService) SubmitPayment(ctx context.Context, payment domain.Payment, submitterID string) error {
-	// Check if submitter is manager or admin
-	if !s.isManager(ctx, payment.MessID, submitterID) {
-		return errors.New("only manager or admin can record payments")
+// UpdateServiceCost changes the name, amount or split of a cost while its
+// month is open, e.g. to adjust a cost copied from a template.
+func (s *FinanceService) UpdateServiceCost(ctx context.Context, update domain.ServiceCost, userID string) error {
+	cost, err := s.repo.GetServiceCostByID(ctx, update.ID)
+	if err != nil || cost == nil || cost.IsDeleted() || cost.MessID != update.MessID {
+		return notFound("service cost")
+	}
+	if err := s.authorize(ctx, cost.MessID, userID, accessManager, "update service costs"); err != nil {
+		return err
+	}
+	if update.Amount <= 0 {
+		return errors.New("amount must be positive")
+	}
+	if err := validateCostShares(update.Amount, update.Shares); err != nil {
+		return err
+	}
+	if err := s.checkMonthLock(ctx, cost.MessID, cost.Month); err != nil {
+		return err
 	}
 
-	if payment.ID == "" {
-		payment.ID = utils.GenerateID("PAY", 4)
+	before := *cost
+	if update.Name != "" {
+		cost.Name = update.Name
 	}
+	cost.Amount = update.Amount
+	cost.Shares = update.Shares
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateServiceCost(ctx, cost); err != nil {
+			return err
+		}
+		// Replace the ledger posting with one for the new amount
+		if err := s.ledger.ReverseSource(ctx, cost.MessID, domain.SourceServiceCost, cost.ID, userID); err != nil {
+			return err
+		}
+		if err := s.ledger.PostServiceCost(ctx, cost, userID); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, cost.MessID, cost.Month, userID, domain.AuditUpdate, domain.AuditEntityServiceCost, cost.ID, before, cost)
+	})
+}
+
+func (s *FinanceService) DeleteServiceCost(ctx context.Context, messID, costID, userID string) error {
+	cost, err := s.repo.GetServiceCostByID(ctx, costID)
+	if err != nil || cost == nil || cost.IsDeleted() || cost.MessID != messID {
+		return notFound("service cost")
+	}
+	if err := s.authorize(ctx, messID, userID, accessManager, "delete service costs"); err != nil {
+		return err
+	}
+
+	if err := s.checkMonthLock(ctx, cost.MessID, cost.Month); err != nil {
+		return err
+	}
+	before := *cost
+	now := time.Now()
+	cost.DeletedAt = &now
+	cost.DeletedBy = userID
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.DeleteServiceCost(ctx, costID, userID, now); err != nil {
+			return err
+		}
+		if err := s.ledger.ReverseSource(ctx, cost.MessID, domain.SourceServiceCost, cost.ID, userID); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, cost.MessID, cost.Month, userID, domain.AuditDelete, domain.AuditEntityServiceCost, cost.ID, before, cost)
+	})
+}
+
+// SubmitPayment records cash handed over by a member. Payments recorded by a
+// manager are approved immediately; members may only submit their own, which
+// wait as pending until a manager verifies them.
+func (s *FinanceService) SubmitPayment(ctx context.Context, payment domain.Payment, submitterID string) error {
+	if err := s.authorize(ctx, payment.MessID, submitterID, accessMember, "submit payments"); err != nil {
+		return err
+	}
+	isManager := s.isManager(ctx, payment.MessID, submitterID)
 
-	// If UserID is not provided (e.g. member self-submitting, which is not allowed by isManager check here,
-	// but for completeness), default to submitterID.
 	// Managers MUST provide the UserID of the member who paid.
+	// Members always pay for themselves.
 	if payment.UserID == "" {
 		payment.UserID = submitterID
 	}
+	if !isManager && payment.UserID != submitterID {
+		return fmt.Errorf("%w: members can only submit their own payments", domain.ErrForbidden)
+	}
+
+	if payment.Amount <= 0 {
+		return fmt.Errorf("%w: amount must be positive", domain.ErrInvalid)
+	}
+	if payment.Method == "" {
+		payment.Method = domain.PaymentMethodCash
+	}
+	if !payment.Method.IsValid() {
+		return fmt.Errorf("%w: invalid payment method", domain.ErrInvalid)
+	}
 
-	payment.Status = "approved"
+	if payment.ID == "" {
+		payment.ID = utils.GenerateID("PAY", 4)
+	}
+
+	// Never trust review fields from the request body
+	payment.Kind = domain.PaymentKindPayment
+	payment.Reason = ""
+	payment.RejectedBy = ""
+	payment.ReversalOf = ""
+	payment.ReversedBy = ""
+	payment.SettlementID = ""
+	if isManager {
+		payment.Status = "approved"
+		payment.ApprovedBy = submitterID
+	} else {
+		payment.Status = "pending"
+		payment.ApprovedBy = ""
+	}
+	payment.SubmittedBy = submitterID
 	payment.CreatedAt = time.Now()
-	// TODO: Check Month Lock
-	return s.repo.CreatePayment(ctx, &payment)
+	// A payment has no date of its own: it counts towards the month it names,
+	// or the current one if it names none
+	if payment.Month == "" {
+		payment.Month = monthOf(payment.CreatedAt)
+	}
+	if err := validateMonth(payment.Month); err != nil {
+		return err
+	}
+
+	if err := s.checkMonthLock(ctx, payment.MessID, payment.Month); err != nil {
+		return err
+	}
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreatePayment(ctx, &payment); err != nil {
+			return err
+		}
+		if payment.Status == "approved" {
+			if err := s.ledger.PostPayment(ctx, &payment, submitterID); err != nil {
+				return err
+			}
+		}
+		return s.audit.Record(ctx, payment.MessID, payment.Month, submitterID, domain.AuditCreate, domain.AuditEntityPayment, payment.ID, nil, payment)
+	})
 }
 
-func (s *FinanceService) VerifyPayment(ctx context.Context, paymentID, approverID string) error {
-	// 1. Get payment to find messID
+func (s *FinanceService) VerifyPayment(ctx context.Context, messID, paymentID, approverID string) error {
 	payment, err := s.repo.GetPaymentByID(ctx, paymentID)
-	if err != nil || payment == nil {
-		return errors.New("payment not found")
+	if err != nil || payment == nil || payment.MessID != messID {
+		return notFound("payment")
+	}
+	if err := s.authorize(ctx, payment.MessID, approverID, accessManager, "verify payments"); err != nil {
+		return err
+	}
+	if payment.Status != "pending" {
+		return fmt.Errorf("%w: only pending payments can be verified", domain.ErrInvalid)
+	}
+
+	if err := s.checkMonthLock(ctx, payment.MessID, payment.Month); err != nil {
+		return err
+	}
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdatePaymentStatus(ctx, paymentID, "approved", approverID); err != nil {
+			return err
+		}
+		before := *payment
+		payment.Status = "approved"
+		payment.ApprovedBy = approverID
+		if err := s.ledger.PostPayment(ctx, payment, approverID); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, payment.MessID, payment.Month, approverID, domain.AuditApprove, domain.AuditEntityPayment, payment.ID, before, payment)
+	})
+}
+
+func (s *FinanceService) RejectPayment(ctx context.Context, messID, paymentID, approverID, reason string) error {
+	payment, err := s.repo.GetPaymentByID(ctx, paymentID)
+	if err != nil || payment == nil || payment.MessID != messID {
+		return notFound("payment")
+	}
+
+	if err := s.authorize(ctx, payment.MessID, approverID, accessManager, "reject payments"); err != nil {
+		return err
+	}
+	if payment.Status != "pending" {
+		return fmt.Errorf("%w: only pending payments can be rejected", domain.ErrInvalid)
+	}
+	if reason == "" {
+		return fmt.Errorf("%w: a reason is required to reject a payment", domain.ErrInvalid)
+	}
+
+	if err := s.checkMonthLock(ctx, payment.MessID, payment.Month); err != nil {
+		return err
+	}
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.RejectPayment(ctx, paymentID, approverID, reason); err != nil {
+			return err
+		}
+		after := *payment
+		after.Status = "rejected"
+		after.RejectedBy = approverID
+		after.Reason = reason
+		return s.audit.Record(ctx, payment.MessID, payment.Month, approverID, domain.AuditReject, domain.AuditEntityPayment, payment.ID, payment, after)
+	})
+}
+
+// ReversePayment cancels an approved payment by recording a compensating
+// negative entry in the same month. The original is kept for history.
+func (s *FinanceService) ReversePayment(ctx context.Context, messID, paymentID, userID, reason string) (*domain.Payment, error) {
+	payment, err := s.repo.GetPaymentByID(ctx, paymentID)
+	if err != nil || payment == nil || payment.MessID != messID {
+		return nil, notFound("payment")
+	}
+
+	if err := s.authorize(ctx, payment.MessID, userID, accessManager, "reverse payments"); err != nil {
+		return nil, err
+	}
+	if payment.Status != "approved" {
+		return nil, fmt.Errorf("%w: only approved payments can be reversed", domain.ErrInvalid)
+	}
+	if payment.Kind == domain.PaymentKindReversal {
+		return nil, fmt.Errorf("%w: a reversal cannot be reversed", domain.ErrInvalid)
+	}
+	if payment.ReversedBy != "" {
+		return nil, fmt.Errorf("%w: payment is already reversed", domain.ErrInvalid)
+	}
+	if reason == "" {
+		return nil, fmt.Errorf("%w: a reason is required to reverse a payment", domain.ErrInvalid)
 	}
 
-	// 2. Check if approver is manager
-	if !s.isManager(ctx, payment.MessID, approverID) {
-		return errors.New("only manager can verify payments")
+	if err := s.checkMonthLock(ctx, payment.MessID, payment.Month); err != nil {
+		return nil, err
 	}
 
-	return s.repo.UpdatePaymentStatus(ctx, paymentID, "approved", approverID)
+	reversal := &domain.Payment{
+		ID:         utils.GenerateID("PAY", 4),
+		UserID:     payment.UserID,
+		MessID:     payment.MessID,
+		Amount:     -payment.Amount,
+		Type:       payment.Type,
+		Status:     "approved",
+		Month:      payment.Month,
+		CreatedAt:  time.Now(),
+		ApprovedBy: userID,
+		Kind:       domain.PaymentKindReversal,
+		Reason:     reason,
+		ReversalOf: payment.ID,
+
+		SettlesMonth: payment.SettlesMonth,
+	}
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreatePayment(ctx, reversal); err != nil {
+			return err
+		}
+		if err := s.ledger.PostPayment(ctx, reversal, userID); err != nil {
+			return err
+		}
+		if err := s.repo.MarkPaymentReversed(ctx, payment.ID, reversal.ID); err != nil {
+			return err
+		}
+		// The original is marked reversed and the reversal is a new payment,
+		// so each gets its own entry under its own ID
+		after := *payment
+		after.ReversedBy = reversal.ID
+		if err := s.audit.Record(ctx, payment.MessID, payment.Month, userID, domain.AuditReverse, domain.AuditEntityPayment, payment.ID, payment, &after); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, reversal.MessID, reversal.Month, userID, domain.AuditCreate, domain.AuditEntityPayment, reversal.ID, nil, reversal)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reversal, nil
+}
+
+// RefundMember pays out a member's positive closing balance for the month,
+// typically when they leave. The refund is a negative approved payment. A
+// closed month is refunded from its snapshot and the refund is booked in the
+// next open month, like a settlement transfer.
+func (s *FinanceService) RefundMember(ctx context.Context, messID, memberID, month string, amount domain.Money, reason, userID string) (*domain.Payment, error) {
+	if err := s.authorize(ctx, messID, userID, accessBookkeeper, "refund members"); err != nil {
+		return nil, err
+	}
+
+	bookMonth, settlesMonth := month, ""
+	summary, err := s.closedSummary(ctx, messID, month)
+	if err != nil {
+		return nil, err
+	}
+	if summary != nil {
+		if bookMonth, err = s.settlementMonth(ctx, messID, month); err != nil {
+			return nil, err
+		}
+		settlesMonth = month
+	} else {
+		if err := s.checkMonthLock(ctx, messID, month); err != nil {
+			return nil, err
+		}
+		if summary, err = s.GenerateMonthlySummary(ctx, messID, month); err != nil {
+			return nil, err
+		}
+	}
+
+	ms, ok := summary.MemberSummaries[memberID]
+	if !ok || ms.ClosingBalance <= 0 {
+		return nil, fmt.Errorf("%w: member has no positive balance to refund", domain.ErrInvalid)
+	}
+	if amount <= 0 {
+		amount = ms.ClosingBalance
+	}
+	if amount > ms.ClosingBalance {
+		return nil, fmt.Errorf("%w: refund exceeds the member's balance", domain.ErrInvalid)
+	}
+
+	refund := &domain.Payment{
+		ID:         utils.GenerateID("PAY", 4),
+		UserID:     memberID,
+		MessID:     messID,
+		Amount:     -amount,
+		Type:       payoutType(ms),
+		Status:     "approved",
+		Month:      bookMonth,
+		CreatedAt:  time.Now(),
+		ApprovedBy: userID,
+		Kind:       domain.PaymentKindRefund,
+		Reason:     reason,
+
+		SettlesMonth: settlesMonth,
+	}
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreatePayment(ctx, refund); err != nil {
+			return err
+		}
+		if err := s.ledger.PostPayment(ctx, refund, userID); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, messID, bookMonth, userID, domain.AuditRefund, domain.AuditEntityPayment, refund.ID, nil, refund)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refund, nil
 }
 
 func (s *FinanceService) GetPendingPayments(ctx context.Context, messID, month string) ([]domain.Payment, error) {
@@ -117,37 +434,206 @@ func (s *FinanceService) GetMemberPayments(ctx context.Context, messID, userID s
 	return s.repo.GetMemberPayments(ctx, messID, userID)
 }
 
-func (s *FinanceService) UpsertDailyMeal(ctx context.Context, meal domain.DailyMeal) error {
-	// TODO: Check Month Lock
-	return s.repo.UpsertDailyMeal(ctx, &meal)
+func (s *FinanceService) UpsertDailyMeal(ctx context.Context, meal domain.DailyMeal, userID string) error {
+	if err := s.checkMealOwner(ctx, meal.MessID, meal.UserID, userID); err != nil {
+		return err
+	}
+	if err := setMealMonth(&meal); err != nil {
+		return err
+	}
+	if err := validateMeal(&meal, s.mealConfig(ctx, meal.MessID)); err != nil {
+		return err
+	}
+	if err := s.checkMonthLock(ctx, meal.MessID, meal.Month); err != nil {
+		return err
+	}
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.findDailyMeal(ctx, meal.MessID, meal.UserID, meal.Date)
+		if err != nil {
+			return err
+		}
+		return s.saveDailyMeal(ctx, before, &meal, userID)
+	})
+}
+
+// findDailyMeal returns a member's meals for a date, or nil if none were entered.
+func (s *FinanceService) findDailyMeal(ctx context.Context, messID, userID string, date time.Time) (*domain.DailyMeal, error) {
+	meals, err := s.repo.GetDailyMealsForDate(ctx, messID, date)
+	if err != nil {
+		return nil, err
+	}
+	for i := range meals {
+		if meals[i].UserID == userID {
+			meals[i].Normalize()
+			return &meals[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// saveDailyMeal upserts a day's meals and records the change in the audit log.
+func (s *FinanceService) saveDailyMeal(ctx context.Context, before, meal *domain.DailyMeal, actorID string) error {
+	if err := s.repo.UpsertDailyMeal(ctx, meal); err != nil {
+		return err
+	}
+	action := domain.AuditUpdate
+	var previous interface{}
+	if before != nil {
+		previous = before
+	} else {
+		action = domain.AuditCreate
+	}
+	entityID := meal.UserID + ":" + meal.Date.Format("2006-01-02")
+	return s.audit.Record(ctx, meal.MessID, meal.Month, actorID, action, domain.AuditEntityDailyMeal, entityID, previous, meal)
 }
 
 func (s *FinanceService) GetDailyMeals(ctx context.Context, messID, month string) ([]domain.DailyMeal, error) {
-	return s.repo.GetDailyMeals(ctx, messID, month)
+	meals, err := s.repo.GetDailyMeals(ctx, messID, month)
+	if err != nil {
+		return nil, err
+	}
+	for i := range meals {
+		meals[i].Normalize()
+	}
+	return meals, nil
 }
 
-func (s *FinanceService) BatchUpdateMeals(ctx context.Context, meals []domain.DailyMeal, userID string) error {
-	if len(meals) == 0 {
-		return nil
+// mealConfig returns the meal configuration of the mess, or the default.
+func (s *FinanceService) mealConfig(ctx context.Context, messID string) domain.MealConfig {
+	mess, err := s.messRepo.GetByID(ctx, messID)
+	if err != nil || mess == nil {
+		return domain.DefaultMealConfig()
 	}
+	return mess.MealSlotsConfig()
+}
 
-	// Check if user is a member of the mess
-	if !s.isMember(ctx, meals[0].MessID, userID) {
-		return errors.New("only members can update meals")
+// setMealMonth sets the meal's month from its date.
+func setMealMonth(meal *domain.DailyMeal) error {
+	month, err := monthFor(meal.Month, meal.Date)
+	if err != nil {
+		return err
 	}
+	meal.Month = month
+	return nil
+}
 
-	for _, meal := range meals {
-		if err := s.repo.UpsertDailyMeal(ctx, &meal); err != nil {
-			return err
+// validateMeal normalizes a day's meals and checks them against the mess's slots.
+func validateMeal(meal *domain.DailyMeal, config domain.MealConfig) error {
+	meal.Normalize()
+	for key, count := range meal.Meals {
+		if _, ok := config.Slot(key); !ok {
+			return fmt.Errorf("unknown meal slot %q", key)
+		}
+		if count < 0 {
+			return fmt.Errorf("meal count for %s cannot be negative", key)
 		}
 	}
+	if meal.GuestMeals < 0 {
+		return errors.New("guest meals cannot be negative")
+	}
 	return nil
 }
 
+// BatchUpdateMeals saves a batch of daily meals for one mess. Members may
+// only change their own meals before the cutoff; managers may change any
+// member's. Each row is checked on its own: rejected rows are reported in
+// the result, and the valid ones are saved together in one transaction.
+func (s *FinanceService) BatchUpdateMeals(ctx context.Context, messID string, meals []domain.DailyMeal, userID string) (*domain.MealBatchResult, error) {
+	if err := s.authorize(ctx, messID, userID, accessMember, "update meals"); err != nil {
+		return nil, err
+	}
+	mess, err := s.messRepo.GetByID(ctx, messID)
+	if err != nil || mess == nil {
+		return nil, notFound("mess")
+	}
+
+	activeMembers := make(map[string]bool)
+	for _, m := range mess.Members {
+		if m.Status == "active" {
+			activeMembers[m.UserID] = true
+		}
+	}
+	isManager := s.isManager(ctx, messID, userID)
+	config := mess.MealSlotsConfig()
+
+	result := &domain.MealBatchResult{Results: make([]domain.MealRowResult, 0, len(meals))}
+	lockErrs := make(map[string]error)
+	valid := make([]*domain.DailyMeal, 0, len(meals))
+	for i := range meals {
+		meal := &meals[i]
+		if meal.UserID == "" {
+			meal.UserID = userID
+		}
+
+		rowErr := func() error {
+			if meal.MessID == "" {
+				meal.MessID = messID
+			}
+			if meal.MessID != messID {
+				return errors.New("meal belongs to a different mess")
+			}
+			if meal.Date.IsZero() {
+				return errors.New("date is required")
+			}
+			if err := setMealMonth(meal); err != nil {
+				return err
+			}
+			if !isManager && meal.UserID != userID {
+				return errors.New("members can only update their own meals")
+			}
+			if !activeMembers[meal.UserID] {
+				return errors.New("user is not an active member of this mess")
+			}
+			if err := validateMeal(meal, config); err != nil {
+				return err
+			}
+			if !isManager {
+				if err := checkMealCutoff(config, meal.Date); err != nil {
+					return err
+				}
+			}
+			lockErr, checked := lockErrs[meal.Month]
+			if !checked {
+				lockErr = s.checkMonthLock(ctx, messID, meal.Month)
+				lockErrs[meal.Month] = lockErr
+			}
+			return lockErr
+		}()
+
+		row := domain.MealRowResult{Index: i, UserID: meal.UserID, Date: meal.Date, Status: "updated"}
+		if rowErr != nil {
+			row.Status = "rejected"
+			row.Error = rowErr.Error()
+			result.Rejected++
+		} else {
+			valid = append(valid, meal)
+			result.Updated++
+		}
+		result.Results = append(result.Results, row)
+	}
+
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		for _, meal := range valid {
+			before, err := s.findDailyMeal(ctx, messID, meal.UserID, meal.Date)
+			if err != nil {
+				return err
+			}
+			if err := s.saveDailyMeal(ctx, before, meal, userID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (s *FinanceService) CreateBazar(ctx context.Context, bazar domain.Bazar, submitterID string) error {
 	// Check if user is a member of the mess
-	if !s.isMember(ctx, bazar.MessID, submitterID) {
-		return errors.New("only members can add bazar entries")
+	if err := s.authorize(ctx, bazar.MessID, submitterID, accessMember, "add bazar entries"); err != nil {
+		return err
 	}
 
 	if bazar.ID == "" {
@@ -158,9 +644,27 @@ func (s *FinanceService) CreateBazar(ctx context.Context, bazar domain.Bazar, su
 		bazar.Date = time.Now()
 	}
 	bazar.CreatedBy = submitterID
+	month, err := monthFor(bazar.Month, bazar.Date)
+	if err != nil {
+		return err
+	}
+	bazar.Month = month
+	if err := normalizeBazarItems(&bazar); err != nil {
+		return err
+	}
 
-	// TODO: Check Month Lock
-	return s.repo.CreateBazar(ctx, &bazar)
+	if err := s.checkMonthLock(ctx, bazar.MessID, bazar.Month); err != nil {
+		return err
+	}
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateBazar(ctx, &bazar); err != nil {
+			return err
+		}
+		if err := s.ledger.PostBazar(ctx, &bazar, submitterID); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, bazar.MessID, bazar.Month, submitterID, domain.AuditCreate, domain.AuditEntityBazar, bazar.ID, nil, bazar)
+	})
 }
 
 func (s *FinanceService) GetPendingBazars(ctx context.Context, messID, month string) ([]domain.Bazar, error) {
@@ -177,32 +681,46 @@ func (s *FinanceService) GetPendingBazars(ctx context.Context, messID, month str
 	return pending, nil
 }
 
-func (s *FinanceService) ApproveBazar(ctx context.Context, bazarID, approverID string) error {
-	// 1. Get bazar to find messID
+func (s *FinanceService) ApproveBazar(ctx context.Context, messID, bazarID, approverID string) error {
 	bazar, err := s.repo.GetBazarByID(ctx, bazarID)
-	if err != nil || bazar == nil {
-		return errors.New("bazar entry not found")
+	if err != nil || bazar == nil || bazar.IsDeleted() || bazar.MessID != messID {
+		return notFound("bazar entry")
 	}
-
-	// 2. Check if approver is manager
-	if !s.isManager(ctx, bazar.MessID, approverID) {
-		return errors.New("only manager can approve bazar entries")
+	if err := s.authorize(ctx, bazar.MessID, approverID, accessManager, "approve bazar entries"); err != nil {
+		return err
 	}
 
-	return s.repo.ApproveBazar(ctx, bazarID)
+	if err := s.checkMonthLock(ctx, bazar.MessID, bazar.Month); err != nil {
+		return err
+	}
+	if bazar.Status == "approved" {
+		return nil
+	}
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.ApproveBazar(ctx, bazarID); err != nil {
+			return err
+		}
+		before := *bazar
+		bazar.Status = "approved"
+		if err := s.ledger.PostBazar(ctx, bazar, approverID); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, bazar.MessID, bazar.Month, approverID, domain.AuditApprove, domain.AuditEntityBazar, bazar.ID, before, bazar)
+	})
 }
 
 func (s *FinanceService) UpdateBazar(ctx context.Context, bazar domain.Bazar, userID string) error {
 	existing, err := s.repo.GetBazarByID(ctx, bazar.ID)
-	if err != nil || existing == nil {
-		return errors.New("bazar entry not found")
+	if err != nil || existing == nil || existing.IsDeleted() || existing.MessID != bazar.MessID {
+		return notFound("bazar entry")
+	}
+	// The buyer or a manager can change an entry
+	if err := s.authorizeOwner(ctx, existing.MessID, existing.BuyerID, userID, "update bazar entries"); err != nil {
+		return err
 	}
 
-	// Permission: Manager or Owner (if pending/approved? User said Manager mainly)
-	// Let's allow Manager OR Owner
-	isManager := s.isManager(ctx, existing.MessID, userID)
-	if existing.BuyerID != userID && !isManager {
-		return errors.New("unauthorized to update this bazar entry")
+	if err := s.checkMonthLock(ctx, existing.MessID, existing.Month); err != nil {
+		return err
 	}
 
 	// Preserve immutable fields
@@ -213,28 +731,63 @@ func (s *FinanceService) UpdateBazar(ctx context.Context, bazar domain.Bazar, us
 	}
 
 	// If simplistic update, just update amount/items
+	before := *existing
 	existing.Amount = bazar.Amount
 	existing.Items = bazar.Items
-	// We don't change BuyerID or CreatedBy here to preserve original audit trail
-	// unless the user specifically wants to track "last updated by".
-	// For now, keeping it simple as requested.
+	// Line items left out of the request are kept; an empty list clears them
+	if bazar.LineItems != nil {
+		existing.LineItems = bazar.LineItems
+	}
+	if err := normalizeBazarItems(existing); err != nil {
+		return err
+	}
+	// We don't change BuyerID or CreatedBy here to preserve the original
+	// entry; who changed what is recorded in the audit log.
 	// existing.Date = bazar.Date // Date update is tricky if not passed correctly
 
-	return s.repo.UpdateBazar(ctx, existing)
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateBazar(ctx, existing); err != nil {
+			return err
+		}
+		if existing.Status == "approved" {
+			// Replace the ledger posting with one for the new amount
+			if err := s.ledger.ReverseSource(ctx, existing.MessID, domain.SourceBazar, existing.ID, userID); err != nil {
+				return err
+			}
+			if err := s.ledger.PostBazar(ctx, existing, userID); err != nil {
+				return err
+			}
+		}
+		return s.audit.Record(ctx, existing.MessID, existing.Month, userID, domain.AuditUpdate, domain.AuditEntityBazar, existing.ID, before, existing)
+	})
 }
 
-func (s *FinanceService) DeleteBazar(ctx context.Context, bazarID, userID string) error {
+func (s *FinanceService) DeleteBazar(ctx context.Context, messID, bazarID, userID string) error {
 	existing, err := s.repo.GetBazarByID(ctx, bazarID)
-	if err != nil || existing == nil {
-		return errors.New("bazar entry not found")
+	if err != nil || existing == nil || existing.IsDeleted() || existing.MessID != messID {
+		return notFound("bazar entry")
+	}
+	if err := s.authorizeOwner(ctx, messID, existing.BuyerID, userID, "delete bazar entries"); err != nil {
+		return err
 	}
 
-	isManager := s.isManager(ctx, existing.MessID, userID)
-	if existing.BuyerID != userID && !isManager {
-		return errors.New("unauthorized to delete this bazar entry")
+	if err := s.checkMonthLock(ctx, existing.MessID, existing.Month); err != nil {
+		return err
 	}
 
-	return s.repo.DeleteBazar(ctx, bazarID)
+	before := *existing
+	now := time.Now()
+	existing.DeletedAt = &now
+	existing.DeletedBy = userID
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.DeleteBazar(ctx, bazarID, userID, now); err != nil {
+			return err
+		}
+		if err := s.ledger.ReverseSource(ctx, existing.MessID, domain.SourceBazar, existing.ID, userID); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, existing.MessID, existing.Month, userID, domain.AuditDelete, domain.AuditEntityBazar, existing.ID, before, existing)
+	})
 }
 
 func (s *FinanceService) GetBazars(ctx context.Context, messID, month string) ([]domain.Bazar, error) {
@@ -243,103 +796,343 @@ func (s *FinanceService) GetBazars(ctx context.Context, messID, month string) ([
 
 // --- History / Month Lock ---
 
-func (s *FinanceService) RequestUnlock(ctx context.Context, messID, month string) error {
+func (s *FinanceService) RequestUnlock(ctx context.Context, messID, month, userID string) error {
+	if err := s.authorize(ctx, messID, userID, accessMember, "request an unlock"); err != nil {
+		return err
+	}
 	lock, err := s.repo.GetMonthLock(ctx, messID, month)
 	if err != nil {
 		return err
 	}
 	if lock == nil {
 		lock = &domain.MonthLock{
+			ID:       utils.GenerateID("LOCK", 6),
 			MessID:   messID,
 			Month:    month,
 			IsLocked: true, // Default to locked if created late
 		}
 	}
+	before := *lock
 	lock.UnlockRequested = true
-	return s.repo.UpsertMonthLock(ctx, lock)
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpsertMonthLock(ctx, lock); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, messID, month, userID, domain.AuditUpdate, domain.AuditEntityMonthLock, lock.ID, before, lock)
+	})
 }
 
 func (s *FinanceService) GetLockStatus(ctx context.Context, messID, month string) (*domain.MonthLock, error) {
-	return s.repo.GetMonthLock(ctx, messID, month)
+	lock, err := s.repo.GetMonthLock(ctx, messID, month)
+	if err != nil || lock == nil {
+		return lock, err
+	}
+	// Report the effective state so an expired temporary unlock shows as locked
+	lock.IsLocked = lock.IsActive(time.Now())
+	return lock, nil
 }
 
-func (s *FinanceService) SetLockStatus(ctx context.Context, messID, month string, isLocked bool, expiryDuration time.Duration) error {
+// SetLockStatus locks or unlocks a month. Managers can lock; reopening a
+// month takes an admin.
+func (s *FinanceService) SetLockStatus(ctx context.Context, messID, month string, isLocked bool, expiryDuration time.Duration, userID string) error {
+	if isLocked {
+		if err := s.authorize(ctx, messID, userID, accessManager, "lock a month"); err != nil {
+			return err
+		}
+	} else if err := s.authorize(ctx, messID, userID, accessAdmin, "unlock a month"); err != nil {
+		return err
+	}
 	lock, err := s.repo.GetMonthLock(ctx, messID, month)
 	if err != nil {
 		return err
 	}
 	if lock == nil {
 		lock = &domain.MonthLock{
+			ID:     utils.GenerateID("LOCK", 6),
 			MessID: messID,
 			Month:  month,
 		}
 	}
+	before := *lock
 	lock.IsLocked = isLocked
 	lock.UnlockRequested = false // Reset request
+	lock.UnlockExpiry = time.Time{}
 	if !isLocked && expiryDuration > 0 {
 		lock.UnlockExpiry = time.Now().Add(expiryDuration)
 	}
-	return s.repo.UpsertMonthLock(ctx, lock)
+	action := domain.AuditLock
+	if !isLocked {
+		action = domain.AuditUnlock
+	}
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpsertMonthLock(ctx, lock); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, messID, month, userID, action, domain.AuditEntityMonthLock, lock.ID, before, lock)
+	})
+}
+
+// --- Month Closing ---
+
+// CloseMonth locks the month and freezes its summary as a new snapshot version.
+func (s *FinanceService) CloseMonth(ctx context.Context, messID, month, userID string) (*domain.SummarySnapshot, error) {
+	if err := s.authorize(ctx, messID, userID, accessManager, "close a month"); err != nil {
+		return nil, err
+	}
+
+	// Later months opened with this month's closing balances, which closing
+	// it again would change under them
+	last, err := s.repo.GetLastClosedMonth(ctx, messID)
+	if err != nil {
+		return nil, err
+	}
+	if last > month {
+		return nil, fmt.Errorf("%w: %s is already closed; an earlier month cannot be closed after it", domain.ErrConflict, last)
+	}
+
+	// Lock first so no write can slip in between computing and storing
+	if err := s.SetLockStatus(ctx, messID, month, true, 0, userID); err != nil {
+		return nil, err
+	}
+
+	summary, err := s.GenerateMonthlySummary(ctx, messID, month)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := s.repo.GetLatestSummarySnapshot(ctx, messID, month)
+	if err != nil {
+		return nil, err
+	}
+	version := 1
+	if latest != nil {
+		version = latest.Version + 1
+	}
+
+	snapshot := &domain.SummarySnapshot{
+		ID:       utils.GenerateID("SNAP", 6),
+		MessID:   messID,
+		Month:    month,
+		Version:  version,
+		Summary:  *summary,
+		ClosedBy: userID,
+		ClosedAt: time.Now(),
+	}
+	// The month stays locked if this fails; closing again retries it
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateSummarySnapshot(ctx, snapshot); err != nil {
+			return err
+		}
+		if err := s.ledger.PostAllocation(ctx, messID, summary, userID); err != nil {
+			return err
+		}
+		if err := s.carryForwardBalances(ctx, snapshot); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, messID, month, userID, domain.AuditClose, domain.AuditEntitySnapshot, snapshot.ID, nil, snapshot)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.Summary.SnapshotVersion = version
+	return snapshot, nil
+}
+
+// monthsToClose lists the months from the one after the last closed month,
+// or the month the mess was created, through the current month. The current
+// month is always included, so it is closed again if it already was.
+func (s *FinanceService) monthsToClose(ctx context.Context, mess *domain.Mess) ([]string, error) {
+	current := monthOf(time.Now())
+	month := current
+	last, err := s.repo.GetLastClosedMonth(ctx, mess.ID)
+	if err != nil {
+		return nil, err
+	}
+	if last != "" {
+		if month, err = nextMonth(last); err != nil {
+			return nil, err
+		}
+	} else if !mess.CreatedAt.IsZero() {
+		month = monthOf(mess.CreatedAt)
+	}
+	if month > current {
+		month = current
+	}
+
+	var months []string
+	for ; month <= current; month, _ = nextMonth(month) {
+		months = append(months, month)
+	}
+	return months, nil
+}
+
+// carryForwardBalances writes every member account's ledger balance at the
+// end of the snapshot's month as an opening entry of the month after it. The
+// ledger covers members missing from the summary, e.g. those who left
+// earlier with money still owed. The house part is tracked from the
+// summaries and the meal part is the rest.
+func (s *FinanceService) carryForwardBalances(ctx context.Context, snapshot *domain.SummarySnapshot) error {
+	next, err := nextMonth(snapshot.Month)
+	if err != nil {
+		return err
+	}
+
+	balances, err := s.ledger.MemberBalances(ctx, snapshot.MessID, snapshot.Month)
+	if err != nil {
+		return err
+	}
+	openings, err := s.repo.GetOpeningBalances(ctx, snapshot.MessID, snapshot.Month)
+	if err != nil {
+		return err
+	}
+	previous := make(map[string]domain.OpeningBalance)
+	for _, o := range openings {
+		previous[o.UserID] = o
+	}
+
+	// A month closed again replaces the openings it carried before. Anyone
+	// carried last time who has no balance now is carried at zero, so their
+	// old opening does not linger.
+	carried, err := s.repo.GetOpeningBalances(ctx, snapshot.MessID, next)
+	if err != nil {
+		return err
+	}
+	for _, o := range carried {
+		if _, ok := balances[o.UserID]; !ok {
+			balances[o.UserID] = 0
+		}
+	}
+
+	for userID, balance := range balances {
+		houseBalance := previous[userID].HouseBalance + snapshot.Summary.MemberSummaries[userID].HouseBalance
+		entry := &domain.OpeningBalance{
+			ID:            utils.GenerateID("OPEN", 6),
+			MessID:        snapshot.MessID,
+			UserID:        userID,
+			Month:         next,
+			SourceMonth:   snapshot.Month,
+			SourceVersion: snapshot.Version,
+			HouseBalance:  houseBalance,
+			MealBalance:   balance - houseBalance,
+			Balance:       balance,
+			CreatedAt:     snapshot.ClosedAt,
+		}
+		if err := s.repo.UpsertOpeningBalance(ctx, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetMemberBalanceHistory returns the member's running balance across every closed month.
+func (s *FinanceService) GetMemberBalanceHistory(ctx context.Context, messID, targetUserID, userID string) ([]domain.BalanceHistoryEntry, error) {
+	if err := s.authorizeReader(ctx, messID, targetUserID, userID, "view balance history"); err != nil {
+		return nil, err
+	}
+
+	balances, err := s.repo.GetMemberOpeningBalances(ctx, messID, targetUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each entry holds the closing balance of its source month; the opening
+	// balance of that month is the entry carried into it, if any.
+	openingByMonth := make(map[string]domain.Money)
+	for _, b := range balances {
+		openingByMonth[b.Month] = b.Balance
+	}
+
+	history := []domain.BalanceHistoryEntry{}
+	for _, b := range balances {
+		opening := openingByMonth[b.SourceMonth]
+		history = append(history, domain.BalanceHistoryEntry{
+			Month:          b.SourceMonth,
+			OpeningBalance: opening,
+			MonthBalance:   b.Balance - opening,
+			ClosingBalance: b.Balance,
+		})
+	}
+	return history, nil
+}
+
+// GetFinalSummary serves the latest frozen snapshot while the month is closed,
+// and the live summary otherwise.
+func (s *FinanceService) GetFinalSummary(ctx context.Context, messID, month string) (*domain.MonthSummary, error) {
+	lock, err := s.repo.GetMonthLock(ctx, messID, month)
+	if err != nil {
+		return nil, err
+	}
+	if lock.IsActive(time.Now()) {
+		snapshot, err := s.repo.GetLatestSummarySnapshot(ctx, messID, month)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot != nil {
+			summary := snapshot.Summary
+			summary.SnapshotVersion = snapshot.Version
+			return &summary, nil
+		}
+	}
+	return s.GenerateMonthlySummary(ctx, messID, month)
+}
+
+// GetSummarySnapshots lists every closed version of the month, oldest first.
+func (s *FinanceService) GetSummarySnapshots(ctx context.Context, messID, month string) ([]domain.SummarySnapshot, error) {
+	snapshots, err := s.repo.GetSummarySnapshots(ctx, messID, month)
+	if err != nil {
+		return nil, err
+	}
+	for i := range snapshots {
+		snapshots[i].Summary.SnapshotVersion = snapshots[i].Version
+	}
+	return snapshots, nil
 }
 
 // --- Summary Calculation Logic ---
 
-type MemberSummary struct {
-	UserID       string  `json:"user_id"`
-	Name         string  `json:"name"`
-	TotalMeals   float64 `json:"total_meals"`
-	MealCost     float64 `json:"meal_cost"`
-	ServiceShare float64 `json:"service_share"`
-	BazarSpent   float64 `json:"bazar_spent"`   // Credit (Meals)
-	HousePaid    float64 `json:"house_paid"`    // Credit (House)
-	MealPaid     float64 `json:"meal_paid"`     // Credit (Meals)
-	TotalPaid    float64 `json:"total_paid"`    // Total Cash Payments
-	TotalDebit   float64 `json:"total_debit"`   // ServiceShare + MealCost
-	TotalCredit  float64 `json:"total_credit"`  // BazarSpent + HousePaid + MealPaid
-	HouseBalance float64 `json:"house_balance"` // HousePaid - ServiceShare
-	MealBalance  float64 `json:"meal_balance"`  // BazarSpent + MealPaid - MealCost
-	Balance      float64 `json:"balance"`       // TotalCredit - TotalDebit
-}
-
-type MonthSummary struct {
-	Month            string                   `json:"month"`
-	TotalServiceCost float64                  `json:"total_service_cost"`
-	TotalMealCost    float64                  `json:"total_meal_cost"`
-	MealRate         float64                  `json:"meal_rate"`
-	TotalMeals       float64                  `json:"total_meals"`
-	MemberSummaries  map[string]MemberSummary `json:"member_summaries"`
-}
-
-func (s *FinanceService) GenerateMonthlySummary(ctx context.Context, messID, month string) (*MonthSummary, error) {
+func (s *FinanceService) GenerateMonthlySummary(ctx context.Context, messID, month string) (*domain.MonthSummary, error) {
 	// 1. Get Mess for member details (Rent)
 	mess, _ := s.messRepo.GetByID(ctx, messID)
 	if mess == nil {
-		return nil, errors.New("mess not found")
+		return nil, notFound("mess")
 	}
 
 	// 2. Fetch Service Costs (Shared)
 	serviceCosts, _ := s.repo.GetServiceCosts(ctx, messID, month)
-	totalService := 0.0
+	var totalService domain.Money
 	for _, c := range serviceCosts {
 		if c.Status == "approved" {
 			totalService += c.Amount
 		}
 	}
 
-	// 3. Fetch Meals
+	// 3. Fetch Meals, weighted by the mess's meal configuration
+	mealConfig := mess.MealSlotsConfig()
+	hasActivity := make(map[string]bool)
 	meals, _ := s.repo.GetDailyMeals(ctx, messID, month)
 	totalMeals := 0.0
 	userMeals := make(map[string]float64)
+	userGuestMeals := make(map[string]int)
+	userGuestCharge := make(map[string]domain.Money)
+	var totalGuestCharge domain.Money
 	for _, m := range meals {
-		dailyTotal := m.Breakfast + m.Lunch + m.Dinner + float64(m.GuestMeals)
+		m.Normalize()
+		dailyTotal := mealConfig.Units(m)
 		userMeals[m.UserID] += dailyTotal
+		userGuestMeals[m.UserID] += m.GuestMeals
+		if charge := mealConfig.GuestCharge(m); charge != 0 {
+			userGuestCharge[m.UserID] += charge
+			totalGuestCharge += charge
+		}
+		hasActivity[m.UserID] = true
 		totalMeals += dailyTotal
 	}
 
 	// 4. Fetch Bazars (Approved Only)
 	bazars, _ := s.repo.GetBazars(ctx, messID, month)
-	totalBazar := 0.0
-	userBazarSpent := make(map[string]float64)
+	var totalBazar domain.Money
+	userBazarSpent := make(map[string]domain.Money)
 	for _, b := range bazars {
 		if b.Status == "approved" {
 			totalBazar += b.Amount
@@ -349,11 +1142,12 @@ func (s *FinanceService) GenerateMonthlySummary(ctx context.Context, messID, mon
 
 	// 5. Fetch Payments (Approved Only)
 	payments, _ := s.repo.GetPayments(ctx, messID, month)
-	userTotalPaid := make(map[string]float64)
-	userHousePaid := make(map[string]float64)
-	userMealPaid := make(map[string]float64)
+	userTotalPaid := make(map[string]domain.Money)
+	userHousePaid := make(map[string]domain.Money)
+	userMealPaid := make(map[string]domain.Money)
 	for _, p := range payments {
 		if p.Status == "approved" {
+			hasActivity[p.UserID] = true
 			userTotalPaid[p.UserID] += p.Amount
 			if p.Type == domain.PaymentTypeHouse {
 				userHousePaid[p.UserID] += p.Amount
@@ -363,27 +1157,58 @@ func (s *FinanceService) GenerateMonthlySummary(ctx context.Context, messID, mon
 		}
 	}
 
-	// 6. Calculations
-	mealRate := 0.0
-	if totalMeals > 0 {
-		mealRate = totalBazar / totalMeals
+	// 6. Opening balances carried from the previous closed month
+	openings, _ := s.repo.GetOpeningBalances(ctx, messID, month)
+	userOpening := make(map[string]domain.Money)
+	for _, o := range openings {
+		userOpening[o.UserID] += o.Balance
+		hasActivity[o.UserID] = true
 	}
 
-	activeMemberCount := 0.0
+	// 7. Calculations
+	// Fixed-price guest meals are charged first; the rest of the bazar is
+	// shared by weighted meal units. The meal rate is rounded to the paisa
+	// for display only. Meal costs are exact shares of the shared bazar by
+	// meal units, with leftover paisa handed out so they always add up.
+	sharedBazar := totalBazar - totalGuestCharge
+	mealRate := sharedBazar.RatePer(totalMeals)
+	mealUserIDs := make([]string, 0, len(userMeals))
+	for userID := range userMeals {
+		mealUserIDs = append(mealUserIDs, userID)
+	}
+	sort.Strings(mealUserIDs)
+	mealWeights := make([]float64, len(mealUserIDs))
+	for i, userID := range mealUserIDs {
+		mealWeights[i] = userMeals[userID]
+	}
+	userMealCost := make(map[string]domain.Money)
+	for i, part := range sharedBazar.Allocate(mealWeights) {
+		userMealCost[mealUserIDs[i]] = part
+	}
+	for userID, charge := range userGuestCharge {
+		userMealCost[userID] += charge
+	}
+
+	// Un-shared service costs are prorated by the days each member belonged
+	// to the mess during the month, using the membership as it was then.
+	daysPresent := make(map[string]int)
+	presentMemberIDs := []string{}
+	presentWeights := []float64{}
 	for _, m := range mess.Members {
-		if m.Status == "active" {
-			activeMemberCount++
+		days, _ := m.DaysPresent(month)
+		if days == 0 {
+			continue
 		}
-	}
-	if activeMemberCount == 0 {
-		activeMemberCount = 1
+		daysPresent[m.UserID] = days
+		presentMemberIDs = append(presentMemberIDs, m.UserID)
+		presentWeights = append(presentWeights, float64(days))
 	}
 
 	// Calculate Per-Person Service Cost (Old way: totalService / activeCount)
 	// New way: We need to calculate how much EACH user owes for service costs.
 	// Since costs can now be split unequally, we can't use a single "perPersonService" rate for everyone consistently if there are custom splits.
 	// We will calculate a map of UserID -> ServiceDebt
-	userServiceDebt := make(map[string]float64)
+	userServiceDebt := make(map[string]domain.Money)
 
 	for _, cost := range serviceCosts {
 		if cost.Status != "approved" {
@@ -396,12 +1221,9 @@ func (s *FinanceService) GenerateMonthlySummary(ctx context.Context, messID, mon
 				userServiceDebt[share.UserID] += share.Amount
 			}
 		} else {
-			// Equal Split (Default)
-			splitAmount := cost.Amount / activeMemberCount
-			for _, m := range mess.Members {
-				if m.Status == "active" {
-					userServiceDebt[m.UserID] += splitAmount
-				}
+			// Split by days present (Default), leftover paisa to the earliest members
+			for i, part := range cost.Amount.Allocate(presentWeights) {
+				userServiceDebt[presentMemberIDs[i]] += part
 			}
 		}
 	}
@@ -409,14 +1231,17 @@ func (s *FinanceService) GenerateMonthlySummary(ctx context.Context, messID, mon
 	// perPersonService is now variable per user, so we remove the single variable definition
 	// and use the map lookup inside the loop.
 
-	summaries := make(map[string]MemberSummary)
+	summaries := make(map[string]domain.MemberSummary)
 	for _, m := range mess.Members {
-		if m.Status != "active" {
+		// Members appear for the months they belonged to the mess. Members who
+		// left or are suspended also appear while they have meals or money in
+		// the month, so refunds and reversals recorded meanwhile are settled here.
+		if daysPresent[m.UserID] == 0 && (m.Status != "left" && m.Status != "suspended" || !hasActivity[m.UserID]) {
 			continue
 		}
 
 		mealsCount := userMeals[m.UserID]
-		mealCost := mealsCount * mealRate
+		mealCost := userMealCost[m.UserID]
 		bazarSpent := userBazarSpent[m.UserID]
 		totalPaid := userTotalPaid[m.UserID]
 		housePaid := userHousePaid[m.UserID]
@@ -433,6 +1258,7 @@ func (s *FinanceService) GenerateMonthlySummary(ctx context.Context, messID, mon
 
 		houseBalance := housePaid - individualServiceCost
 		mealBalance := mealPaid - mealCost
+		openingBalance := userOpening[m.UserID]
 
 		userName := m.Name
 		if userName == "" {
@@ -444,10 +1270,13 @@ func (s *FinanceService) GenerateMonthlySummary(ctx context.Context, messID, mon
 			}
 		}
 
-		summaries[m.UserID] = MemberSummary{
+		summaries[m.UserID] = domain.MemberSummary{
 			UserID:       m.UserID,
 			Name:         userName,
+			DaysPresent:  daysPresent[m.UserID],
 			TotalMeals:   mealsCount,
+			GuestMeals:   userGuestMeals[m.UserID],
+			GuestCharge:  userGuestCharge[m.UserID],
 			MealCost:     mealCost,
 			ServiceShare: individualServiceCost, // Variable now
 			BazarSpent:   bazarSpent,
@@ -459,10 +1288,13 @@ func (s *FinanceService) GenerateMonthlySummary(ctx context.Context, messID, mon
 			HouseBalance: houseBalance,
 			MealBalance:  mealBalance,
 			Balance:      balance,
+
+			OpeningBalance: openingBalance,
+			ClosingBalance: openingBalance + balance,
 		}
 	}
 
-	return &MonthSummary{
+	return &domain.MonthSummary{
 		Month:            month,
 		TotalServiceCost: totalService,
 		TotalMealCost:    totalBazar,
@@ -472,32 +1304,69 @@ func (s *FinanceService) GenerateMonthlySummary(ctx context.Context, messID, mon
 	}, nil
 }
 
-func (s *FinanceService) isManager(ctx context.Context, messID, userID string) bool {
-	mess, err := s.messRepo.GetByID(ctx, messID)
-	if err != nil || mess == nil {
-		return false
+// checkMonthLock returns domain.ErrMonthLocked when writes to the month are blocked.
+func (s *FinanceService) checkMonthLock(ctx context.Context, messID, month string) error {
+	lock, err := s.repo.GetMonthLock(ctx, messID, month)
+	if err != nil {
+		return err
 	}
-	for _, m := range mess.Members {
-		if m.UserID == userID {
-			for _, r := range m.Roles {
-				if r == domain.RoleManager || r == domain.RoleAdmin {
-					return true
-				}
-			}
-		}
+	if lock.IsActive(time.Now()) {
+		return fmt.Errorf("%w: %s", domain.ErrMonthLocked, month)
+	}
+	return nil
+}
+
+// nextMonth returns the YYYY-MM month key following the given one.
+func nextMonth(month string) (string, error) {
+	t, err := time.Parse("2006-01", month)
+	if err != nil {
+		return "", fmt.Errorf("invalid month %q", month)
 	}
-	return false
+	return monthOf(t.AddDate(0, 1, 0)), nil
+}
+
+// monthOf formats a date as the YYYY-MM month key used by finance records.
+func monthOf(t time.Time) string {
+	return t.Format("2006-01")
+}
+
+// monthFor returns the month key of a record's date. The lock of that month
+// is what guards the write, so a client-sent month that disagrees is refused
+// rather than trusted.
+func monthFor(month string, date time.Time) (string, error) {
+	want := monthOf(date)
+	if month != "" && month != want {
+		return "", fmt.Errorf("%w: month %s does not match the date %s", domain.ErrInvalid, month, date.Format("2006-01-02"))
+	}
+	return want, nil
+}
+
+func (s *FinanceService) isManager(ctx context.Context, messID, userID string) bool {
+	return isMessManager(ctx, s.messRepo, messID, userID)
 }
 
 func (s *FinanceService) isMember(ctx context.Context, messID, userID string) bool {
-	mess, err := s.messRepo.GetByID(ctx, messID)
-	if err != nil || mess == nil {
-		return false
+	return isActiveMember(ctx, s.messRepo, messID, userID)
+}
+
+func (s *FinanceService) authorize(ctx context.Context, messID, userID string, level accessLevel, action string) error {
+	return authorize(ctx, s.messRepo, messID, userID, level, action)
+}
+
+// authorizeOwner lets members act on their own records and managers on
+// anyone's.
+func (s *FinanceService) authorizeOwner(ctx context.Context, messID, ownerID, userID, action string) error {
+	if ownerID == userID {
+		return s.authorize(ctx, messID, userID, accessMember, action)
 	}
-	for _, m := range mess.Members {
-		if m.UserID == userID && m.Status == "active" {
-			return true
-		}
+	return s.authorize(ctx, messID, userID, accessManager, action+" for other members")
+}
+
+// authorizeReader lets members read their own records and managers anyone's,
+// also after the mess is dissolved.
+func (s *FinanceService) authorizeReader(ctx context.Context, messID, ownerID, userID, action string) error {
+	if ownerID == userID {
+		return s.authorize(ctx, messID, userID, accessReader, action)
 	}
-	return false
+	return s.authorize(ctx, messID, userID, accessBookkeeper, action+" for other members")
 }
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAddServiceCostShares(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(member("a", domain.RoleManager), member("b"))
+
+	cost := domain.ServiceCost{
+		MessID: env.messID,
+		Month:  testMonth,
+		Name:   "Internet",
+		Amount: 1001,
+		Shares: []domain.CostShare{{UserID: "a", Amount: 500}, {UserID: "b", Amount: 500}},
+	}
+	if err := env.finance.AddServiceCost(ctx, cost, "a"); !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("got %v for shares a paisa short, want ErrInvalid", err)
+	}
+	if len(env.repo.costs) != 0 || len(env.journal.entries) != 0 {
+		t.Fatalf("a refused cost recorded %d costs and %d entries", len(env.repo.costs), len(env.journal.entries))
+	}
+
+	cost.Shares[1].Amount = 501
+	if err := env.finance.AddServiceCost(ctx, cost, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if len(env.repo.costs) != 1 || len(env.journal.entries) != 1 {
+		t.Errorf("got %d costs and %d entries, want one of each", len(env.repo.costs), len(env.journal.entries))
+	}
+}
+
+func TestSummarySplitsToThePaisa(t *testing.T) {
+	env := newTestEnv(member("a", domain.RoleManager), member("b"), member("c"))
+	env.repo.bazars = []domain.Bazar{
+		{ID: "BZ-1", MessID: env.messID, BuyerID: "a", Amount: 1000, Status: "approved", Month: testMonth},
+	}
+	env.repo.meals = []domain.DailyMeal{
+		{MessID: env.messID, UserID: "a", Month: testMonth, Lunch: 1},
+		{MessID: env.messID, UserID: "b", Month: testMonth, Lunch: 1, Dinner: 1},
+		{MessID: env.messID, UserID: "c", Month: testMonth, Breakfast: 1, Lunch: 1, Dinner: 1, GuestMeals: 1},
+	}
+	env.repo.costs = []domain.ServiceCost{
+		{ID: "COST-1", MessID: env.messID, Month: testMonth, Name: "Gas", Amount: 100, Status: "approved"},
+		{ID: "COST-2", MessID: env.messID, Month: testMonth, Name: "Rent", Amount: 500, Status: "approved",
+			Shares: []domain.CostShare{{UserID: "a", Amount: 200}, {UserID: "c", Amount: 300}}},
+	}
+
+	summary, err := env.finance.GenerateMonthlySummary(context.Background(), env.messID, testMonth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]struct{ meal, service domain.Money }{
+		"a": {meal: 143, service: 34 + 200},
+		"b": {meal: 286, service: 33},
+		"c": {meal: 571, service: 33 + 300},
+	}
+	var mealTotal, serviceTotal domain.Money
+	for userID, w := range want {
+		ms := summary.MemberSummaries[userID]
+		if ms.MealCost != w.meal || ms.ServiceShare != w.service {
+			t.Errorf("%s: got meal %s service %s, want %s and %s", userID, ms.MealCost, ms.ServiceShare, w.meal, w.service)
+		}
+		mealTotal += ms.MealCost
+		serviceTotal += ms.ServiceShare
+	}
+	if mealTotal != summary.TotalMealCost || serviceTotal != summary.TotalServiceCost {
+		t.Errorf("charges add up to %s and %s, want %s and %s", mealTotal, serviceTotal, summary.TotalMealCost, summary.TotalServiceCost)
+	}
+	if summary.MealRate != 143 {
+		t.Errorf("got meal rate %s, want 1.43", summary.MealRate)
+	}
+}
+
+func TestSummaryProratesServiceCosts(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, time.June, d, 14, 30, 0, 0, time.Local)
+	}
+	joined := member("b")
+	joined.JoinedAt = day(16)
+	leftAt := day(10)
+	left := member("c")
+	left.Status = "left"
+	left.LeftAt = &leftAt
+	pending := member("d")
+	pending.Status = "pending"
+	later := member("e")
+	later.JoinedAt = day(1).AddDate(0, 1, 0)
+
+	env := newTestEnv(member("a", domain.RoleManager), joined, left, pending, later)
+	env.repo.costs = []domain.ServiceCost{
+		{ID: "COST-1", MessID: env.messID, Month: testMonth, Name: "Rent", Amount: 5500, Status: "approved"},
+	}
+
+	summary, err := env.finance.GenerateMonthlySummary(context.Background(), env.messID, testMonth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]struct {
+		days    int
+		service domain.Money
+	}{
+		"a": {days: 30, service: 3000},
+		"b": {days: 15, service: 1500},
+		"c": {days: 10, service: 1000},
+	}
+	if len(summary.MemberSummaries) != len(want) {
+		t.Errorf("got %d members in the summary, want %d", len(summary.MemberSummaries), len(want))
+	}
+	for userID, w := range want {
+		ms, ok := summary.MemberSummaries[userID]
+		if !ok {
+			t.Errorf("%s missing from the summary", userID)
+			continue
+		}
+		if ms.DaysPresent != w.days || ms.ServiceShare != w.service {
+			t.Errorf("%s: got %d days and %s, want %d days and %s", userID, ms.DaysPresent, ms.ServiceShare, w.days, w.service)
+		}
+	}
+}
+
+func TestSummaryWeighsMeals(t *testing.T) {
+	env := newTestEnv(member("a", domain.RoleManager), member("b"), member("c"))
+	env.messes.messes[env.messID].MealConfig = &domain.MealConfig{
+		Slots: []domain.MealSlot{
+			{Key: "breakfast", Name: "Breakfast", Weight: 0.5},
+			{Key: "lunch", Name: "Lunch", Weight: 1},
+			{Key: "dinner", Name: "Dinner", Weight: 1},
+		},
+		GuestWeight: 1,
+		GuestPrice:  domain.Taka(80),
+	}
+	env.repo.bazars = []domain.Bazar{
+		{ID: "BZ-1", MessID: env.messID, BuyerID: "a", Amount: domain.Taka(1000), Status: "approved", Month: testMonth},
+	}
+	env.repo.meals = []domain.DailyMeal{
+		// Written before configurable slots, with only the legacy fields
+		{MessID: env.messID, UserID: "a", Month: testMonth, Breakfast: 1, Lunch: 1},
+		{MessID: env.messID, UserID: "b", Month: testMonth, Meals: map[string]float64{"lunch": 1, "dinner": 1}, GuestMeals: 2},
+	}
+
+	summary, err := env.finance.GenerateMonthlySummary(context.Background(), env.messID, testMonth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 160 for b's guests at the fixed price; the other 840 over 3.5 units
+	if summary.TotalMeals != 3.5 || summary.MealRate != domain.Taka(240) {
+		t.Errorf("got %v units at %s, want 3.5 at 240.00", summary.TotalMeals, summary.MealRate)
+	}
+	want := map[string]struct {
+		units  float64
+		guest  domain.Money
+		meal   domain.Money
+		guests int
+	}{
+		"a": {units: 1.5, meal: domain.Taka(360)},
+		"b": {units: 2, guest: domain.Taka(160), meal: domain.Taka(640), guests: 2},
+		"c": {},
+	}
+	for userID, w := range want {
+		ms := summary.MemberSummaries[userID]
+		if ms.TotalMeals != w.units || ms.GuestCharge != w.guest || ms.MealCost != w.meal || ms.GuestMeals != w.guests {
+			t.Errorf("%s: got %v units, %d guests charged %s, meal cost %s; want %v, %d, %s, %s",
+				userID, ms.TotalMeals, ms.GuestMeals, ms.GuestCharge, ms.MealCost, w.units, w.guests, w.guest, w.meal)
+		}
+	}
+}
+
+func TestUpsertDailyMealChecksSlots(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(member("a", domain.RoleManager))
+	date := time.Date(2024, time.June, 10, 0, 0, 0, 0, time.Local)
+
+	for _, meals := range []map[string]float64{{"iftar": 1}, {"lunch": -1}} {
+		if err := env.finance.UpsertDailyMeal(ctx, domain.DailyMeal{MessID: env.messID, UserID: "a", Date: date, Meals: meals}, "a"); err == nil {
+			t.Errorf("expected %v to be refused", meals)
+		}
+	}
+	if err := env.finance.UpsertDailyMeal(ctx, domain.DailyMeal{MessID: env.messID, UserID: "a", Date: date, GuestMeals: -1}, "a"); err == nil {
+		t.Error("expected negative guest meals to be refused")
+	}
+	if len(env.repo.meals) != 0 || len(env.audit.entries) != 0 {
+		t.Fatalf("refused meals recorded %d meals and %d audit entries", len(env.repo.meals), len(env.audit.entries))
+	}
+
+	if err := env.finance.UpsertDailyMeal(ctx, domain.DailyMeal{MessID: env.messID, UserID: "a", Date: date, Lunch: 1}, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if len(env.audit.entries) != 1 || env.audit.entries[0].Action != domain.AuditCreate {
+		t.Errorf("got audit entries %+v, want one create", env.audit.entries)
+	}
+	if len(env.repo.meals) != 1 || env.repo.meals[0].Meals["lunch"] != 1 || env.repo.meals[0].Month != testMonth {
+		t.Errorf("got %+v, want the legacy lunch stored as a slot in %s", env.repo.meals, testMonth)
+	}
+}
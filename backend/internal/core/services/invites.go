@@ -0,0 +1,256 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"amar-dera/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// --- Invites ---
+
+const (
+	defaultInviteTTL = 7 * 24 * time.Hour
+	maxInviteTTL     = 30 * 24 * time.Hour
+)
+
+var errInviteUnusable = errors.New("this invite has expired, was revoked or is used up")
+
+// CreateInvite issues an invite token for the mess. It can be used maxUses
+// times (once if not set) until it expires after ttl (7 days if not set).
+// Whoever joins with it gets the given roles in addition to member.
+func (s *MessService) CreateInvite(ctx context.Context, messID, adminID string, maxUses int, ttl time.Duration, roles []domain.Role) (*domain.Invite, error) {
+	if err := authorize(ctx, s.repo, messID, adminID, accessAdmin, "create invites"); err != nil {
+		return nil, err
+	}
+
+	if maxUses <= 0 {
+		maxUses = 1
+	}
+	if ttl <= 0 {
+		ttl = defaultInviteTTL
+	}
+	if ttl > maxInviteTTL {
+		return nil, errors.New("invites can be valid for at most 30 days")
+	}
+
+	granted := []domain.Role{domain.RoleMember}
+	for _, role := range roles {
+		switch role {
+		case domain.RoleMember:
+			continue
+		case domain.RoleAdmin, domain.RoleManager:
+		default:
+			return nil, fmt.Errorf("unknown role %q", role)
+		}
+		if (domain.Member{Roles: granted}).HasRole(role) {
+			continue
+		}
+		granted = append(granted, role)
+	}
+	// A mess has a single manager, so only one person may join as manager
+	if maxUses > 1 && (domain.Member{Roles: granted}).HasRole(domain.RoleManager) {
+		return nil, errors.New("an invite granting the manager role must be single-use")
+	}
+
+	now := time.Now()
+	invite := &domain.Invite{
+		ID:        utils.GenerateID("INV", 16),
+		MessID:    messID,
+		Roles:     granted,
+		MaxUses:   maxUses,
+		UsedBy:    []string{},
+		ExpiresAt: now.Add(ttl),
+		CreatedBy: adminID,
+		CreatedAt: now,
+	}
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.invites.Create(ctx, invite); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, messID, monthOf(now), adminID, domain.AuditCreate, domain.AuditEntityInvite, invite.ID, nil, invite)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invite, nil
+}
+
+// GetInvites lists the mess's invites that can still be used.
+func (s *MessService) GetInvites(ctx context.Context, messID, adminID string) ([]domain.Invite, error) {
+	if err := authorize(ctx, s.repo, messID, adminID, accessAdmin, "view invites"); err != nil {
+		return nil, err
+	}
+	invites, err := s.invites.ListByMess(ctx, messID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	outstanding := []domain.Invite{}
+	for _, invite := range invites {
+		if invite.IsUsable(now) {
+			outstanding = append(outstanding, invite)
+		}
+	}
+	return outstanding, nil
+}
+
+// RevokeInvite stops an invite from being used any further.
+func (s *MessService) RevokeInvite(ctx context.Context, messID, inviteID, adminID string) error {
+	if err := authorize(ctx, s.repo, messID, adminID, accessAdmin, "revoke invites"); err != nil {
+		return err
+	}
+	invite, err := s.invites.GetByID(ctx, inviteID)
+	if err != nil {
+		return err
+	}
+	if invite == nil || invite.MessID != messID {
+		return notFound("invite")
+	}
+	if invite.RevokedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	revoked := *invite
+	revoked.RevokedAt = &now
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.invites.Revoke(ctx, inviteID, now); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, messID, monthOf(now), adminID, domain.AuditRevoke, domain.AuditEntityInvite, inviteID, invite, &revoked)
+	})
+}
+
+// GetInvitePreview shows which mess an invite is for, before accepting it.
+func (s *MessService) GetInvitePreview(ctx context.Context, inviteID string) (*domain.InvitePreview, error) {
+	invite, err := s.invites.GetByID(ctx, inviteID)
+	if err != nil {
+		return nil, err
+	}
+	if invite == nil {
+		return nil, notFound("invite")
+	}
+	if !invite.IsUsable(time.Now()) {
+		return nil, errInviteUnusable
+	}
+	mess, err := s.repo.GetByID(ctx, invite.MessID)
+	if err != nil {
+		return nil, err
+	}
+	if mess == nil {
+		return nil, notFound("mess")
+	}
+	if mess.IsDissolved() {
+		return nil, errMessDissolved
+	}
+	return &domain.InvitePreview{
+		MessID:    mess.ID,
+		MessName:  mess.Name,
+		Roles:     invite.Roles,
+		ExpiresAt: invite.ExpiresAt,
+	}, nil
+}
+
+// AcceptInvite makes the user an active member of the invite's mess right
+// away, with the invite's roles. A pending join request to the same mess is
+// approved by it, and a former member is taken back.
+func (s *MessService) AcceptInvite(ctx context.Context, inviteID, userID string) (*domain.Mess, error) {
+	invite, err := s.invites.GetByID(ctx, inviteID)
+	if err != nil {
+		return nil, err
+	}
+	if invite == nil {
+		return nil, notFound("invite")
+	}
+	if !invite.IsUsable(time.Now()) {
+		return nil, errInviteUnusable
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	messID := invite.MessID
+	mess, err := s.repo.GetByID(ctx, messID)
+	if err != nil {
+		return nil, err
+	}
+	if mess == nil {
+		return nil, notFound("mess")
+	}
+	if mess.IsDissolved() {
+		return nil, errMessDissolved
+	}
+
+	member := mess.FindMember(userID)
+	if member != nil && (member.Status == "active" || member.Status == "suspended") {
+		return nil, fmt.Errorf("%w: already a member", domain.ErrConflict)
+	}
+	before := cloneMembers(mess.Members)
+	var previous *domain.Member
+	if member != nil {
+		entry := cloneMember(*member)
+		previous = &entry
+	}
+	if member == nil {
+		mess.Members = append(mess.Members, domain.Member{UserID: userID})
+		member = &mess.Members[len(mess.Members)-1]
+	}
+	member.Name = user.Name
+	member.Roles = invite.Roles
+	member.Status = "active"
+	member.JoinedAt = time.Now()
+	member.LeftAt = nil
+
+	// Only one manager allowed: the invite takes it over from whoever held it
+	if member.HasRole(domain.RoleManager) {
+		for i := range mess.Members {
+			m := &mess.Members[i]
+			if m.UserID == userID {
+				continue
+			}
+			var roles []domain.Role
+			for _, r := range m.Roles {
+				if r != domain.RoleManager {
+					roles = append(roles, r)
+				}
+			}
+			if len(roles) == 0 {
+				roles = append(roles, domain.RoleMember)
+			}
+			m.Roles = roles
+		}
+	}
+
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		redeemed, err := s.invites.Redeem(ctx, inviteID, userID, time.Now())
+		if err != nil {
+			return err
+		}
+		if !redeemed {
+			return errInviteUnusable
+		}
+		if err := s.repo.Update(ctx, mess); err != nil {
+			return err
+		}
+		if err := s.userRepo.AddMess(ctx, userID, messID); err != nil {
+			return err
+		}
+		if err := s.userRepo.SetCurrentMessIfUnset(ctx, userID, messID); err != nil {
+			return err
+		}
+		if err := s.recordMember(ctx, messID, userID, domain.AuditJoin, userID, previous, member); err != nil {
+			return err
+		}
+		return s.recordRoleChanges(ctx, messID, userID, userID, before, mess.Members)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mess, nil
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"context"
+	"log"
+	"strings"
+	"time"
+)
+
+// --- Join Requests ---
+
+// RejectMember turns down a pending join request. The reason, if given, is
+// kept on the requester's account so they can see why.
+func (s *MessService) RejectMember(ctx context.Context, messID, userID, adminID, reason string) error {
+	if err := authorize(ctx, s.repo, messID, adminID, accessAdmin, "reject join requests"); err != nil {
+		return err
+	}
+	return s.dropJoinRequest(ctx, messID, userID, adminID, domain.AuditReject, &domain.JoinRejection{
+		Reason:     strings.TrimSpace(reason),
+		RejectedAt: time.Now(),
+	})
+}
+
+// CancelJoinRequest withdraws the user's own pending join request.
+func (s *MessService) CancelJoinRequest(ctx context.Context, messID, userID string) error {
+	return s.dropJoinRequest(ctx, messID, userID, userID, domain.AuditDelete, nil)
+}
+
+// ExpireJoinRequests drops join requests made before the cutoff and returns
+// how many were dropped. Requesters are told their request expired.
+func (s *MessService) ExpireJoinRequests(ctx context.Context, before time.Time) (int, error) {
+	messes, err := s.repo.GetWithPendingBefore(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, mess := range messes {
+		for _, m := range mess.Members {
+			if m.Status != "pending" || !m.JoinedAt.Before(before) {
+				continue
+			}
+			err := s.dropJoinRequest(ctx, mess.ID, m.UserID, auditSystemActor, domain.AuditReject, &domain.JoinRejection{
+				Expired:    true,
+				RejectedAt: time.Now(),
+			})
+			if err != nil {
+				log.Printf("Failed to expire join request of %s for mess %s: %v", m.UserID, mess.ID, err)
+				continue
+			}
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// dropJoinRequest removes a pending request from both the mess and the
+// requester's account. A rejection, if given, is recorded for the requester,
+// and the audit log records the action by the actor.
+func (s *MessService) dropJoinRequest(ctx context.Context, messID, userID, actorID string, action domain.AuditAction, rejection *domain.JoinRejection) error {
+	mess, err := s.repo.GetByID(ctx, messID)
+	if err != nil {
+		return err
+	}
+	if mess == nil {
+		return notFound("mess")
+	}
+	request := mess.FindMember(userID)
+	if request == nil || request.Status != "pending" {
+		return notFound("join request")
+	}
+
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.RemovePendingMember(ctx, messID, userID); err != nil {
+			return err
+		}
+		if err := s.recordMember(ctx, messID, actorID, action, userID, request, nil); err != nil {
+			return err
+		}
+
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil || user == nil {
+			return err
+		}
+		var joinRequests []string
+		for _, reqID := range user.JoinRequests {
+			if reqID != messID {
+				joinRequests = append(joinRequests, reqID)
+			}
+		}
+		user.JoinRequests = joinRequests
+		if rejection != nil {
+			rejection.MessID = messID
+			rejection.MessName = mess.Name
+			user.JoinRejections = append(withoutRejection(user.JoinRejections, messID), *rejection)
+		}
+		return s.userRepo.Update(ctx, user)
+	})
+}
+
+// withoutRejection drops any earlier rejection from the mess, so a user only
+// sees the latest outcome per mess.
+func withoutRejection(rejections []domain.JoinRejection, messID string) []domain.JoinRejection {
+	var kept []domain.JoinRejection
+	for _, r := range rejections {
+		if r.MessID != messID {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// StartJoinRequestExpirer starts a background goroutine that drops join
+// requests left pending longer than maxAge.
+func StartJoinRequestExpirer(messService *MessService, maxAge time.Duration) {
+	go func() {
+		for {
+			n, err := messService.ExpireJoinRequests(context.Background(), time.Now().Add(-maxAge))
+			if err != nil {
+				log.Printf("Failed to expire join requests: %v", err)
+			}
+			if n > 0 {
+				log.Printf("Expired %d join requests", n)
+			}
+
+			time.Sleep(24 * time.Hour)
+		}
+	}()
+}
@@ -0,0 +1,444 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"amar-dera/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// LedgerService keeps the double-entry journal underneath payments, bazars
+// and service costs. Every finance write posts a balanced entry here.
+type LedgerService struct {
+	repo        domain.LedgerRepository
+	financeRepo domain.FinanceRepository
+	messRepo    domain.MessRepository
+}
+
+func NewLedgerService(repo domain.LedgerRepository, financeRepo domain.FinanceRepository, messRepo domain.MessRepository) *LedgerService {
+	return &LedgerService{repo: repo, financeRepo: financeRepo, messRepo: messRepo}
+}
+
+// --- Posting ---
+
+// PostPayment moves cash between a member and the fund. Negative amounts
+// (reversals, refunds) flow back out of the fund.
+func (s *LedgerService) PostPayment(ctx context.Context, payment *domain.Payment, actorID string) error {
+	member := domain.MemberAccount(payment.UserID)
+	lines := transferLines(domain.AccountFund, member, payment.Amount)
+	desc := fmt.Sprintf("%s payment", payment.Type)
+	if payment.Kind != "" && payment.Kind != domain.PaymentKindPayment {
+		desc = fmt.Sprintf("%s %s", payment.Type, payment.Kind)
+	}
+	return s.post(ctx, payment.MessID, payment.Month, domain.SourcePayment, payment.ID, desc, lines, actorID)
+}
+
+// PostBazar books a bazar as a meal expense paid from the fund.
+func (s *LedgerService) PostBazar(ctx context.Context, bazar *domain.Bazar, actorID string) error {
+	lines := transferLines(domain.AccountExpenseBazar, domain.AccountFund, bazar.Amount)
+	return s.post(ctx, bazar.MessID, bazar.Month, domain.SourceBazar, bazar.ID, "bazar: "+bazar.Items, lines, actorID)
+}
+
+// PostServiceCost books a shared house cost paid from the fund.
+func (s *LedgerService) PostServiceCost(ctx context.Context, cost *domain.ServiceCost, actorID string) error {
+	lines := transferLines(domain.AccountExpenseService, domain.AccountFund, cost.Amount)
+	return s.post(ctx, cost.MessID, cost.Month, domain.SourceServiceCost, cost.ID, "service cost: "+cost.Name, lines, actorID)
+}
+
+// PostAllocation charges the month's expenses to members as computed by the
+// summary. A previous allocation for the month is reversed first, so
+// re-closing a month replaces the charges instead of adding to them.
+func (s *LedgerService) PostAllocation(ctx context.Context, messID string, summary *domain.MonthSummary, actorID string) error {
+	if err := s.ReverseSource(ctx, messID, domain.SourceAllocation, summary.Month, actorID); err != nil {
+		return err
+	}
+
+	userIDs := make([]string, 0, len(summary.MemberSummaries))
+	for userID := range summary.MemberSummaries {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+
+	lines := []domain.JournalLine{}
+	var mealTotal, serviceTotal domain.Money
+	for _, userID := range userIDs {
+		ms := summary.MemberSummaries[userID]
+		charge := ms.MealCost + ms.ServiceShare
+		if charge == 0 {
+			continue
+		}
+		lines = append(lines, domain.JournalLine{Account: domain.MemberAccount(userID), Debit: charge})
+		mealTotal += ms.MealCost
+		serviceTotal += ms.ServiceShare
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	if mealTotal != 0 {
+		lines = append(lines, domain.JournalLine{Account: domain.AccountExpenseBazar, Credit: mealTotal})
+	}
+	if serviceTotal != 0 {
+		lines = append(lines, domain.JournalLine{Account: domain.AccountExpenseService, Credit: serviceTotal})
+	}
+
+	return s.post(ctx, messID, summary.Month, domain.SourceAllocation, summary.Month, "month-end allocation", lines, actorID)
+}
+
+// ReverseSource posts a reversing entry for every live entry of a source
+// document, e.g. when a bazar is edited or deleted.
+func (s *LedgerService) ReverseSource(ctx context.Context, messID string, sourceType domain.JournalSource, sourceID, actorID string) error {
+	entries, err := s.repo.GetEntriesBySource(ctx, messID, sourceType, sourceID)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.ReversalOf != "" || e.ReversedBy != "" {
+			continue
+		}
+
+		lines := make([]domain.JournalLine, len(e.Lines))
+		for i, l := range e.Lines {
+			lines[i] = domain.JournalLine{Account: l.Account, Debit: l.Credit, Credit: l.Debit}
+		}
+		reversal := &domain.JournalEntry{
+			ID:          utils.GenerateID("JRNL", 8),
+			MessID:      e.MessID,
+			Month:       e.Month,
+			SourceType:  e.SourceType,
+			SourceID:    e.SourceID,
+			Description: "reversal: " + e.Description,
+			Lines:       lines,
+			ReversalOf:  e.ID,
+			CreatedBy:   actorID,
+			CreatedAt:   time.Now(),
+		}
+		if err := s.repo.CreateEntry(ctx, reversal); err != nil {
+			return err
+		}
+		if err := s.repo.MarkEntryReversed(ctx, e.ID, reversal.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *LedgerService) post(ctx context.Context, messID, month string, sourceType domain.JournalSource, sourceID, description string, lines []domain.JournalLine, actorID string) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	if !isBalanced(lines) {
+		return errors.New("journal entry is not balanced")
+	}
+	entry := &domain.JournalEntry{
+		ID:          utils.GenerateID("JRNL", 8),
+		MessID:      messID,
+		Month:       month,
+		SourceType:  sourceType,
+		SourceID:    sourceID,
+		Description: description,
+		Lines:       lines,
+		CreatedBy:   actorID,
+		CreatedAt:   time.Now(),
+	}
+	return s.repo.CreateEntry(ctx, entry)
+}
+
+// transferLines debits one account and credits another, flipping sides for
+// negative amounts so every line stays non-negative.
+func transferLines(debitAccount, creditAccount string, amount domain.Money) []domain.JournalLine {
+	if amount == 0 {
+		return nil
+	}
+	if amount < 0 {
+		debitAccount, creditAccount = creditAccount, debitAccount
+		amount = -amount
+	}
+	return []domain.JournalLine{
+		{Account: debitAccount, Debit: amount},
+		{Account: creditAccount, Credit: amount},
+	}
+}
+
+func isBalanced(lines []domain.JournalLine) bool {
+	var debit, credit domain.Money
+	for _, l := range lines {
+		debit += l.Debit
+		credit += l.Credit
+	}
+	return debit == credit
+}
+
+// --- Reports ---
+
+// MemberBalances returns the balance of every member account across all
+// months up to and including the given one, keyed by user ID.
+func (s *LedgerService) MemberBalances(ctx context.Context, messID, month string) (map[string]domain.Money, error) {
+	entries, err := s.repo.GetEntriesThrough(ctx, messID, month)
+	if err != nil {
+		return nil, err
+	}
+	balances := make(map[string]domain.Money)
+	for _, e := range entries {
+		for _, l := range e.Lines {
+			if domain.AccountTypeOf(l.Account) == domain.AccountTypeMember {
+				balances[domain.MemberOfAccount(l.Account)] += normalBalance(domain.AccountTypeMember, l.Debit, l.Credit)
+			}
+		}
+	}
+	return balances, nil
+}
+
+// GetTrialBalance returns every account's totals for the month, from which
+// member balances and the fund position can be read directly.
+func (s *LedgerService) GetTrialBalance(ctx context.Context, messID, month, userID string) ([]domain.AccountBalance, error) {
+	if err := authorize(ctx, s.messRepo, messID, userID, accessReader, "view the ledger"); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.GetEntries(ctx, messID, month)
+	if err != nil {
+		return nil, err
+	}
+
+	byAccount := make(map[string]*domain.AccountBalance)
+	for _, e := range entries {
+		for _, l := range e.Lines {
+			ab, ok := byAccount[l.Account]
+			if !ok {
+				ab = &domain.AccountBalance{
+					Account: l.Account,
+					Type:    domain.AccountTypeOf(l.Account),
+				}
+				if ab.Type == domain.AccountTypeMember {
+					ab.UserID = domain.MemberOfAccount(l.Account)
+				}
+				byAccount[l.Account] = ab
+			}
+			ab.Debit += l.Debit
+			ab.Credit += l.Credit
+		}
+	}
+
+	balances := []domain.AccountBalance{}
+	for _, ab := range byAccount {
+		ab.Balance = normalBalance(ab.Type, ab.Debit, ab.Credit)
+		balances = append(balances, *ab)
+	}
+	sort.Slice(balances, func(i, j int) bool { return balances[i].Account < balances[j].Account })
+	return balances, nil
+}
+
+// GetStatement lists every posting to an account across all months with a
+// running balance. Members may read their own account; managers any account.
+func (s *LedgerService) GetStatement(ctx context.Context, messID, account, userID string) (*domain.AccountStatement, error) {
+	if account == "" {
+		account = domain.MemberAccount(userID)
+	}
+	level := accessReader
+	if account != domain.MemberAccount(userID) {
+		level = accessBookkeeper
+	}
+	if err := authorize(ctx, s.messRepo, messID, userID, level, "view this account"); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.GetAccountEntries(ctx, messID, account)
+	if err != nil {
+		return nil, err
+	}
+
+	accountType := domain.AccountTypeOf(account)
+	statement := &domain.AccountStatement{
+		Account: account,
+		Type:    accountType,
+		Lines:   []domain.StatementLine{},
+	}
+	var debit, credit domain.Money
+	for _, e := range entries {
+		for _, l := range e.Lines {
+			if l.Account != account {
+				continue
+			}
+			debit += l.Debit
+			credit += l.Credit
+			statement.Lines = append(statement.Lines, domain.StatementLine{
+				EntryID:        e.ID,
+				Month:          e.Month,
+				Date:           e.CreatedAt,
+				SourceType:     e.SourceType,
+				SourceID:       e.SourceID,
+				Description:    e.Description,
+				Debit:          l.Debit,
+				Credit:         l.Credit,
+				RunningBalance: normalBalance(accountType, debit, credit),
+			})
+		}
+	}
+	statement.Balance = normalBalance(accountType, debit, credit)
+	return statement, nil
+}
+
+// CheckIntegrity proves that debits equal credits for the month, entry by
+// entry and in total, and reconciles the fund against the source documents.
+func (s *LedgerService) CheckIntegrity(ctx context.Context, messID, month, userID string) (*domain.LedgerIntegrity, error) {
+	if err := authorize(ctx, s.messRepo, messID, userID, accessManager, "check the ledger"); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.GetEntries(ctx, messID, month)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.LedgerIntegrity{
+		Month:             month,
+		EntryCount:        len(entries),
+		UnbalancedEntries: []string{},
+	}
+	var fundDebit, fundCredit domain.Money
+	for _, e := range entries {
+		if !isBalanced(e.Lines) {
+			result.UnbalancedEntries = append(result.UnbalancedEntries, e.ID)
+		}
+		for _, l := range e.Lines {
+			result.TotalDebit += l.Debit
+			result.TotalCredit += l.Credit
+			if l.Account == domain.AccountFund {
+				fundDebit += l.Debit
+				fundCredit += l.Credit
+			}
+		}
+	}
+	result.Balanced = result.TotalDebit == result.TotalCredit && len(result.UnbalancedEntries) == 0
+	result.LedgerFundChange = fundDebit - fundCredit
+
+	recordsChange, err := s.recordsFundChange(ctx, messID, month)
+	if err != nil {
+		return nil, err
+	}
+	result.RecordsFundChange = recordsChange
+	result.FundReconciliation = result.LedgerFundChange == result.RecordsFundChange
+	return result, nil
+}
+
+// recordsFundChange derives the fund movement from approved documents.
+func (s *LedgerService) recordsFundChange(ctx context.Context, messID, month string) (domain.Money, error) {
+	var change domain.Money
+	payments, err := s.financeRepo.GetPayments(ctx, messID, month)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range payments {
+		if p.Status == "approved" {
+			change += p.Amount
+		}
+	}
+	bazars, err := s.financeRepo.GetBazars(ctx, messID, month)
+	if err != nil {
+		return 0, err
+	}
+	for _, b := range bazars {
+		if b.Status == "approved" {
+			change -= b.Amount
+		}
+	}
+	costs, err := s.financeRepo.GetServiceCosts(ctx, messID, month)
+	if err != nil {
+		return 0, err
+	}
+	for _, c := range costs {
+		if c.Status == "approved" {
+			change -= c.Amount
+		}
+	}
+	return change, nil
+}
+
+// SyncMonth posts entries for approved documents that predate the ledger.
+func (s *LedgerService) SyncMonth(ctx context.Context, messID, month, userID string) (int, error) {
+	if err := authorize(ctx, s.messRepo, messID, userID, accessManager, "sync the ledger"); err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	payments, err := s.financeRepo.GetPayments(ctx, messID, month)
+	if err != nil {
+		return posted, err
+	}
+	for i := range payments {
+		if payments[i].Status != "approved" {
+			continue
+		}
+		done, err := s.hasEntries(ctx, messID, domain.SourcePayment, payments[i].ID)
+		if err != nil {
+			return posted, err
+		}
+		if done {
+			continue
+		}
+		if err := s.PostPayment(ctx, &payments[i], userID); err != nil {
+			return posted, err
+		}
+		posted++
+	}
+
+	bazars, err := s.financeRepo.GetBazars(ctx, messID, month)
+	if err != nil {
+		return posted, err
+	}
+	for i := range bazars {
+		if bazars[i].Status != "approved" {
+			continue
+		}
+		done, err := s.hasEntries(ctx, messID, domain.SourceBazar, bazars[i].ID)
+		if err != nil {
+			return posted, err
+		}
+		if done {
+			continue
+		}
+		if err := s.PostBazar(ctx, &bazars[i], userID); err != nil {
+			return posted, err
+		}
+		posted++
+	}
+
+	costs, err := s.financeRepo.GetServiceCosts(ctx, messID, month)
+	if err != nil {
+		return posted, err
+	}
+	for i := range costs {
+		if costs[i].Status != "approved" {
+			continue
+		}
+		done, err := s.hasEntries(ctx, messID, domain.SourceServiceCost, costs[i].ID)
+		if err != nil {
+			return posted, err
+		}
+		if done {
+			continue
+		}
+		if err := s.PostServiceCost(ctx, &costs[i], userID); err != nil {
+			return posted, err
+		}
+		posted++
+	}
+	return posted, nil
+}
+
+func (s *LedgerService) hasEntries(ctx context.Context, messID string, sourceType domain.JournalSource, sourceID string) (bool, error) {
+	entries, err := s.repo.GetEntriesBySource(ctx, messID, sourceType, sourceID)
+	return len(entries) > 0, err
+}
+
+// normalBalance reads an account on its natural side: members are owed what
+// they paid (credit), while the fund and expenses grow with debits.
+func normalBalance(accountType domain.AccountType, debit, credit domain.Money) domain.Money {
+	if accountType == domain.AccountTypeMember {
+		return credit - debit
+	}
+	return debit - credit
+}
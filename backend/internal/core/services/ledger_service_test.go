@@ -0,0 +1,204 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"context"
+	"testing"
+)
+
+func TestLedgerPostingsBalance(t *testing.T) {
+	ctx := context.Background()
+	env := newSettlementEnv()
+
+	for i := range env.repo.payments {
+		if err := env.ledger.PostPayment(ctx, &env.repo.payments[i], "a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	refund := domain.Payment{ID: "PAY-3", MessID: env.messID, UserID: "a", Amount: -50, Type: domain.PaymentTypeMeal, Kind: domain.PaymentKindRefund, Month: testMonth}
+	if err := env.ledger.PostPayment(ctx, &refund, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.ledger.PostBazar(ctx, &env.repo.bazars[0], "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.ledger.PostServiceCost(ctx, &env.repo.costs[0], "a"); err != nil {
+		t.Fatal(err)
+	}
+	summary, err := env.finance.GenerateMonthlySummary(ctx, env.messID, testMonth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := env.ledger.PostAllocation(ctx, env.messID, summary, "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(env.journal.entries) != 6 {
+		t.Fatalf("got %d entries, want 6", len(env.journal.entries))
+	}
+	for _, e := range env.journal.entries {
+		if !isBalanced(e.Lines) {
+			t.Errorf("%s entry %q is not balanced: %+v", e.SourceType, e.Description, e.Lines)
+		}
+		for _, l := range e.Lines {
+			if l.Debit < 0 || l.Credit < 0 {
+				t.Errorf("%s entry %q has a negative line %+v", e.SourceType, e.Description, l)
+			}
+		}
+	}
+
+	balances, err := env.ledger.GetTrialBalance(ctx, env.messID, testMonth, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]domain.Money{
+		domain.AccountFund:           -350,
+		domain.AccountExpenseBazar:   0,
+		domain.AccountExpenseService: 0,
+		domain.MemberAccount("a"):    100,
+		domain.MemberAccount("b"):    -450,
+	}
+	checkBalances(t, balances, want)
+}
+
+func TestLedgerReversalNetsToZero(t *testing.T) {
+	ctx := context.Background()
+	env := newSettlementEnv()
+
+	bazar := &env.repo.bazars[0]
+	if err := env.ledger.PostBazar(ctx, bazar, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.ledger.ReverseSource(ctx, env.messID, domain.SourceBazar, bazar.ID, "a"); err != nil {
+		t.Fatal(err)
+	}
+	// A second reversal finds nothing live to reverse
+	if err := env.ledger.ReverseSource(ctx, env.messID, domain.SourceBazar, bazar.ID, "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(env.journal.entries) != 2 {
+		t.Fatalf("got %d entries, want the posting and one reversal", len(env.journal.entries))
+	}
+	original, reversal := env.journal.entries[0], env.journal.entries[1]
+	if original.ReversedBy != reversal.ID || reversal.ReversalOf != original.ID {
+		t.Errorf("entries not linked: reversed_by %q, reversal_of %q", original.ReversedBy, reversal.ReversalOf)
+	}
+
+	balances, err := env.ledger.GetTrialBalance(ctx, env.messID, testMonth, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ab := range balances {
+		if ab.Debit != ab.Credit || ab.Balance != 0 {
+			t.Errorf("%s: debit %v credit %v, want them to net to zero", ab.Account, ab.Debit, ab.Credit)
+		}
+	}
+}
+
+func TestLedgerSyncMonth(t *testing.T) {
+	ctx := context.Background()
+	env := newSettlementEnv()
+	env.repo.payments = append(env.repo.payments, domain.Payment{ID: "PAY-3", MessID: env.messID, UserID: "b", Amount: 80, Status: "pending", Month: testMonth})
+
+	if _, err := env.ledger.SyncMonth(ctx, env.messID, testMonth, "b"); err == nil {
+		t.Error("expected members to be refused")
+	}
+
+	posted, err := env.ledger.SyncMonth(ctx, env.messID, testMonth, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posted != 4 {
+		t.Errorf("got %d entries posted, want the 4 approved documents", posted)
+	}
+	if posted, _ = env.ledger.SyncMonth(ctx, env.messID, testMonth, "a"); posted != 0 {
+		t.Errorf("a second sync posted %d entries, want 0", posted)
+	}
+
+	integrity, err := env.ledger.CheckIntegrity(ctx, env.messID, testMonth, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !integrity.Balanced || !integrity.FundReconciliation {
+		t.Errorf("got %+v, want balanced and reconciled", integrity)
+	}
+	if integrity.LedgerFundChange != -300 {
+		t.Errorf("got fund change %v, want -300", integrity.LedgerFundChange)
+	}
+}
+
+func checkBalances(t *testing.T, got []domain.AccountBalance, want map[string]domain.Money) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d accounts %+v, want %d", len(got), got, len(want))
+	}
+	for _, ab := range got {
+		w, ok := want[ab.Account]
+		if !ok {
+			t.Errorf("unexpected account %s", ab.Account)
+			continue
+		}
+		if ab.Balance != w {
+			t.Errorf("%s: got balance %v, want %v", ab.Account, ab.Balance, w)
+		}
+	}
+}
+
+func TestMemberBalancesMatchSummary(t *testing.T) {
+	ctx := context.Background()
+	env := newSettlementEnv()
+
+	// c left in May still owed 100, so is missing from June's summary
+	leftAt := testJoined.AddDate(0, 4, 19)
+	gone := member("c")
+	gone.Status = "left"
+	gone.LeftAt = &leftAt
+	mess := env.messes.messes[env.messID]
+	mess.Members = append(mess.Members, gone)
+	early := domain.Payment{ID: "PAY-0", MessID: env.messID, UserID: "c", Amount: 100, Type: domain.PaymentTypeMeal, Status: "approved", Month: "2024-05"}
+	if err := env.ledger.PostPayment(ctx, &early, "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := env.ledger.SyncMonth(ctx, env.messID, testMonth, "a"); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := env.finance.CloseMonth(ctx, env.messID, testMonth, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	balances, err := env.ledger.MemberBalances(ctx, env.messID, testMonth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for userID, ms := range snapshot.Summary.MemberSummaries {
+		if balances[userID] != ms.ClosingBalance {
+			t.Errorf("%s: ledger balance %s, summary closing balance %s", userID, balances[userID], ms.ClosingBalance)
+		}
+	}
+	if _, ok := snapshot.Summary.MemberSummaries["c"]; ok || balances["c"] != 100 {
+		t.Errorf("got c in the summary: %v, ledger balance %s; want only the ledger to hold c's 100", ok, balances["c"])
+	}
+
+	openings, err := env.repo.GetOpeningBalances(ctx, env.messID, "2024-07")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(openings) != len(balances) {
+		t.Fatalf("got %d openings for July, want %d", len(openings), len(balances))
+	}
+	for _, o := range openings {
+		if o.Balance != balances[o.UserID] || o.HouseBalance+o.MealBalance != o.Balance {
+			t.Errorf("%s: got opening %+v, want balance %s", o.UserID, o, balances[o.UserID])
+		}
+	}
+
+	if _, err := env.finance.CloseMonth(ctx, env.messID, "2024-07", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.finance.CloseMonth(ctx, env.messID, testMonth, "a"); err == nil {
+		t.Error("expected closing June again after July to be refused")
+	}
+}
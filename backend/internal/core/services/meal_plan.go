@@ -0,0 +1,405 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"amar-dera/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+const maxMealOffDays = 90
+
+// --- Schedules ---
+
+// GetMealSchedule returns a member's default meals. Members see their own;
+// managers can look up anyone in the mess.
+func (s *FinanceService) GetMealSchedule(ctx context.Context, messID, targetUserID, userID string) (*domain.MealSchedule, error) {
+	if targetUserID == "" {
+		targetUserID = userID
+	}
+	if err := s.checkMealOwner(ctx, messID, targetUserID, userID); err != nil {
+		return nil, err
+	}
+	schedule, err := s.repo.GetMealSchedule(ctx, messID, targetUserID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		schedule = &domain.MealSchedule{MessID: messID, UserID: targetUserID, Meals: map[string]float64{}}
+	}
+	return schedule, nil
+}
+
+func (s *FinanceService) GetMealSchedules(ctx context.Context, messID, userID string) ([]domain.MealSchedule, error) {
+	if err := s.authorize(ctx, messID, userID, accessManager, "view all meal schedules"); err != nil {
+		return nil, err
+	}
+	schedules, err := s.repo.GetMealSchedules(ctx, messID)
+	if err != nil {
+		return nil, err
+	}
+	if schedules == nil {
+		schedules = []domain.MealSchedule{}
+	}
+	return schedules, nil
+}
+
+// SetMealSchedule saves a member's default meals. They are filled into the
+// member's daily meals as each day's cutoff passes.
+func (s *FinanceService) SetMealSchedule(ctx context.Context, schedule domain.MealSchedule, userID string) (*domain.MealSchedule, error) {
+	if schedule.UserID == "" {
+		schedule.UserID = userID
+	}
+	if err := s.checkMealOwner(ctx, schedule.MessID, schedule.UserID, userID); err != nil {
+		return nil, err
+	}
+
+	day := domain.DailyMeal{Meals: schedule.Meals}
+	if err := validateMeal(&day, s.mealConfig(ctx, schedule.MessID)); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetMealSchedule(ctx, schedule.MessID, schedule.UserID)
+	if err != nil {
+		return nil, err
+	}
+	action := domain.AuditCreate
+	var before interface{}
+	if existing != nil {
+		schedule.ID = existing.ID
+		action = domain.AuditUpdate
+		before = existing
+	} else {
+		schedule.ID = utils.GenerateID("SCHD", 4)
+	}
+	schedule.Meals = day.Meals
+	schedule.UpdatedBy = userID
+	schedule.UpdatedAt = time.Now()
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpsertMealSchedule(ctx, &schedule); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, schedule.MessID, monthOf(schedule.UpdatedAt), userID, action, domain.AuditEntityMealSchedule, schedule.ID, before, schedule)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// --- Meal-offs ---
+
+// CreateMealOff turns a member's meals off for a range of days. Members
+// cannot reach back past the cutoff; managers can. Meals already entered
+// for those days have the covered slots cleared.
+func (s *FinanceService) CreateMealOff(ctx context.Context, off domain.MealOff, userID string) (*domain.MealOff, error) {
+	if off.UserID == "" {
+		off.UserID = userID
+	}
+	if err := s.checkMealOwner(ctx, off.MessID, off.UserID, userID); err != nil {
+		return nil, err
+	}
+
+	off.From = utcDate(off.From)
+	off.To = utcDate(off.To)
+	if off.From.IsZero() || off.To.IsZero() {
+		return nil, errors.New("from and to dates are required")
+	}
+	if off.To.Before(off.From) {
+		return nil, errors.New("to date must not be before from date")
+	}
+	if off.To.Sub(off.From) > maxMealOffDays*24*time.Hour {
+		return nil, fmt.Errorf("a meal-off can cover at most %d days", maxMealOffDays)
+	}
+
+	config := s.mealConfig(ctx, off.MessID)
+	for _, slot := range off.Slots {
+		if _, ok := config.Slot(slot); !ok {
+			return nil, fmt.Errorf("unknown meal slot %q", slot)
+		}
+	}
+	if !s.isManager(ctx, off.MessID, userID) {
+		if err := checkMealCutoff(config, off.From); err != nil {
+			return nil, err
+		}
+	}
+	for _, month := range monthsBetween(off.From, off.To) {
+		if err := s.checkMonthLock(ctx, off.MessID, month); err != nil {
+			return nil, err
+		}
+	}
+
+	off.ID = utils.GenerateID("OFF", 6)
+	off.CreatedBy = userID
+	off.CreatedAt = time.Now()
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateMealOff(ctx, &off); err != nil {
+			return err
+		}
+		if err := s.audit.Record(ctx, off.MessID, monthOf(off.From), userID, domain.AuditCreate, domain.AuditEntityMealOff, off.ID, nil, off); err != nil {
+			return err
+		}
+
+		for _, month := range monthsBetween(off.From, off.To) {
+			meals, err := s.repo.GetDailyMeals(ctx, off.MessID, month)
+			if err != nil {
+				return err
+			}
+			for _, meal := range meals {
+				if meal.UserID != off.UserID {
+					continue
+				}
+				meal.Normalize()
+				before := meal
+				before.Meals = make(map[string]float64, len(meal.Meals))
+				for slot, count := range meal.Meals {
+					before.Meals[slot] = count
+				}
+				changed := false
+				for slot := range meal.Meals {
+					if off.Covers(meal.Date, slot) {
+						delete(meal.Meals, slot)
+						changed = true
+					}
+				}
+				if changed {
+					if err := s.saveDailyMeal(ctx, &before, &meal, userID); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &off, nil
+}
+
+func (s *FinanceService) GetMealOffs(ctx context.Context, messID string, from, to time.Time, userID string) ([]domain.MealOff, error) {
+	if err := s.authorize(ctx, messID, userID, accessReader, "view meal-offs"); err != nil {
+		return nil, err
+	}
+	offs, err := s.repo.GetMealOffs(ctx, messID, utcDate(from), utcDate(to))
+	if err != nil {
+		return nil, err
+	}
+	if offs == nil {
+		offs = []domain.MealOff{}
+	}
+	return offs, nil
+}
+
+// CancelMealOff removes a meal-off. Members can only cancel one that has not
+// reached the cutoff yet. Meals cleared when it was created stay cleared.
+func (s *FinanceService) CancelMealOff(ctx context.Context, messID, offID, userID string) error {
+	off, err := s.repo.GetMealOffByID(ctx, offID)
+	if err != nil || off == nil || off.MessID != messID {
+		return notFound("meal-off")
+	}
+	if err := s.checkMealOwner(ctx, messID, off.UserID, userID); err != nil {
+		return err
+	}
+	if !s.isManager(ctx, messID, userID) {
+		if err := checkMealCutoff(s.mealConfig(ctx, messID), off.From); err != nil {
+			return err
+		}
+	}
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.DeleteMealOff(ctx, offID); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, messID, monthOf(off.From), userID, domain.AuditDelete, domain.AuditEntityMealOff, off.ID, off, nil)
+	})
+}
+
+// --- Headcount ---
+
+// GetMealHeadcount tells the cook how many meals to prepare on a date:
+// meals already entered, otherwise each member's schedule minus meal-offs.
+func (s *FinanceService) GetMealHeadcount(ctx context.Context, messID string, date time.Time, userID string) (*domain.MealHeadcount, error) {
+	if err := s.authorize(ctx, messID, userID, accessManager, "view the meal headcount"); err != nil {
+		return nil, err
+	}
+	mess, err := s.messRepo.GetByID(ctx, messID)
+	if err != nil || mess == nil {
+		return nil, notFound("mess")
+	}
+
+	date = utcDate(date)
+	entered, err := s.repo.GetDailyMealsForDate(ctx, messID, date)
+	if err != nil {
+		return nil, err
+	}
+	enteredByUser := make(map[string]domain.DailyMeal)
+	for _, m := range entered {
+		m.Normalize()
+		enteredByUser[m.UserID] = m
+	}
+	schedules, err := s.repo.GetMealSchedules(ctx, messID)
+	if err != nil {
+		return nil, err
+	}
+	scheduleByUser := make(map[string]domain.MealSchedule)
+	for _, sch := range schedules {
+		scheduleByUser[sch.UserID] = sch
+	}
+	offs, err := s.repo.GetMealOffs(ctx, messID, date, date)
+	if err != nil {
+		return nil, err
+	}
+
+	frozen := mess.MealSlotsConfig().FrozenThrough(time.Now())
+	headcount := &domain.MealHeadcount{
+		Date:    date,
+		Slots:   make(map[string]float64),
+		Members: []domain.MemberMealsForDate{},
+		Frozen:  !frozen.IsZero() && !date.After(frozen),
+	}
+	for _, m := range mess.Members {
+		if m.Status != "active" {
+			continue
+		}
+		row := domain.MemberMealsForDate{UserID: m.UserID, Name: m.Name}
+		if meal, ok := enteredByUser[m.UserID]; ok {
+			row.Meals = meal.Meals
+			row.GuestMeals = meal.GuestMeals
+			row.Source = "entered"
+		} else if sch, ok := scheduleByUser[m.UserID]; ok && sch.Active {
+			row.Meals = sch.PlannedMeals(date, offs)
+			row.Source = "schedule"
+		} else {
+			continue
+		}
+
+		for slot, count := range row.Meals {
+			headcount.Slots[slot] += count
+		}
+		headcount.GuestMeals += row.GuestMeals
+		headcount.Members = append(headcount.Members, row)
+	}
+	return headcount, nil
+}
+
+// --- Scheduler ---
+
+// MaterializeScheduledMeals fills in daily meals from active schedules for
+// the days whose cutoff has passed (today, and tomorrow after the cutoff).
+// Days a member already has meals for are left alone.
+func (s *FinanceService) MaterializeScheduledMeals(ctx context.Context) error {
+	schedules, err := s.repo.GetActiveMealSchedules(ctx)
+	if err != nil {
+		return err
+	}
+	byMess := make(map[string][]domain.MealSchedule)
+	for _, sch := range schedules {
+		byMess[sch.MessID] = append(byMess[sch.MessID], sch)
+	}
+
+	now := time.Now()
+	for messID, messSchedules := range byMess {
+		mess, err := s.messRepo.GetByID(ctx, messID)
+		if err != nil || mess == nil {
+			continue
+		}
+		today := domain.MealDate(now)
+		last := mess.MealSlotsConfig().FrozenThrough(now)
+		if last.Before(today) {
+			last = today
+		}
+		offs, err := s.repo.GetMealOffs(ctx, messID, today, last)
+		if err != nil {
+			log.Printf("Failed to load meal-offs for mess %s: %v", messID, err)
+			continue
+		}
+
+		for date := today; !date.After(last); date = date.AddDate(0, 0, 1) {
+			month := monthOf(date)
+			if err := s.checkMonthLock(ctx, messID, month); err != nil {
+				continue
+			}
+			for _, sch := range messSchedules {
+				if !s.isMember(ctx, messID, sch.UserID) {
+					continue
+				}
+				meals := sch.PlannedMeals(date, offs)
+				if len(meals) == 0 {
+					continue
+				}
+				meal := &domain.DailyMeal{MessID: messID, UserID: sch.UserID, Date: date, Meals: meals, Month: month}
+				inserted, err := s.repo.InsertDailyMealIfMissing(ctx, meal)
+				if err != nil {
+					log.Printf("Failed to fill scheduled meals for %s in mess %s: %v", sch.UserID, messID, err)
+					continue
+				}
+				if inserted {
+					entityID := meal.UserID + ":" + date.Format("2006-01-02")
+					if err := s.audit.Record(ctx, messID, month, auditSystemActor, domain.AuditCreate, domain.AuditEntityDailyMeal, entityID, nil, meal); err != nil {
+						log.Printf("Failed to audit scheduled meals for %s in mess %s: %v", sch.UserID, messID, err)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// StartMealScheduler starts a background goroutine that fills daily meals
+// from members' default schedules.
+func StartMealScheduler(financeService *FinanceService) {
+	go func() {
+		for {
+			if err := financeService.MaterializeScheduledMeals(context.Background()); err != nil {
+				log.Printf("Failed to fill scheduled meals: %v", err)
+			}
+
+			// Hourly, so tomorrow is filled soon after each mess's cutoff
+			time.Sleep(time.Hour)
+		}
+	}()
+}
+
+// --- Helpers ---
+
+// checkMealOwner allows members to manage their own meals and managers to
+// manage anyone's in the mess.
+func (s *FinanceService) checkMealOwner(ctx context.Context, messID, targetUserID, userID string) error {
+	if err := s.authorizeOwner(ctx, messID, targetUserID, userID, "manage meals"); err != nil {
+		return err
+	}
+	if targetUserID != userID && !s.isMember(ctx, messID, targetUserID) {
+		return errors.New("user is not an active member of this mess")
+	}
+	return nil
+}
+
+// checkMealCutoff rejects changes to a meal date members can no longer change.
+func checkMealCutoff(config domain.MealConfig, date time.Time) error {
+	frozen := config.FrozenThrough(time.Now())
+	if !frozen.IsZero() && !utcDate(date).After(frozen) {
+		return fmt.Errorf("meals for %s can no longer be changed after the %s cutoff", date.Format("2006-01-02"), config.CutoffTime)
+	}
+	return nil
+}
+
+// utcDate truncates a client date to midnight UTC, the form meal dates are stored in.
+func utcDate(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// monthsBetween lists the YYYY-MM months touched by a date range.
+func monthsBetween(from, to time.Time) []string {
+	months := []string{}
+	for m := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC); !m.After(to); m = m.AddDate(0, 1, 0) {
+		months = append(months, monthOf(m))
+	}
+	return months
+}
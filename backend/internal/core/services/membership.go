@@ -0,0 +1,184 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// --- Removal and Suspension ---
+
+// RemoveMember lets an admin take a member out of the mess, as if they had
+// left. The same role safety as leaving applies.
+func (s *MessService) RemoveMember(ctx context.Context, messID, targetUserID, adminID string) error {
+	mess, member, err := s.targetMember(ctx, messID, targetUserID, adminID, "remove members")
+	if err != nil {
+		return err
+	}
+	if member.Status != "active" && member.Status != "suspended" {
+		return notFound("member")
+	}
+	if targetUserID == mess.AdminID {
+		return errors.New("the owner cannot be removed. Transfer ownership first")
+	}
+	if role := lastRoleHolder(mess, targetUserID); role != "" {
+		return fmt.Errorf("this member is the only %s. Please assign another member as %s before removing them", role, role)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, targetUserID)
+	if err != nil || user == nil {
+		return errors.New("user not found")
+	}
+	return s.departMember(ctx, mess, user, adminID, domain.AuditRemove)
+}
+
+// SuspendMember keeps a member out of meals and shared service costs until
+// they are reinstated. The same role safety as leaving applies.
+func (s *MessService) SuspendMember(ctx context.Context, messID, targetUserID, adminID, reason string) error {
+	mess, member, err := s.targetMember(ctx, messID, targetUserID, adminID, "suspend members")
+	if err != nil {
+		return err
+	}
+	if member.Status != "active" {
+		return errors.New("only active members can be suspended")
+	}
+	if targetUserID == mess.AdminID {
+		return errors.New("the owner cannot be suspended. Transfer ownership first")
+	}
+	if role := lastRoleHolder(mess, targetUserID); role != "" {
+		return fmt.Errorf("this member is the only %s. Please assign another member as %s before suspending them", role, role)
+	}
+
+	before := cloneMember(*member)
+	member.Status = "suspended"
+	member.Suspensions = append(member.Suspensions, domain.Suspension{
+		From:   time.Now(),
+		Reason: strings.TrimSpace(reason),
+	})
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, mess); err != nil {
+			return err
+		}
+		return s.recordMember(ctx, messID, adminID, domain.AuditSuspend, targetUserID, &before, member)
+	})
+}
+
+// ReinstateMember ends a member's suspension.
+func (s *MessService) ReinstateMember(ctx context.Context, messID, targetUserID, adminID string) error {
+	mess, member, err := s.targetMember(ctx, messID, targetUserID, adminID, "reinstate members")
+	if err != nil {
+		return err
+	}
+	if member.Status != "suspended" {
+		return errors.New("member is not suspended")
+	}
+
+	before := cloneMember(*member)
+	until := time.Now()
+	for i := range member.Suspensions {
+		if member.Suspensions[i].Until == nil {
+			member.Suspensions[i].Until = &until
+		}
+	}
+	member.Status = "active"
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, mess); err != nil {
+			return err
+		}
+		return s.recordMember(ctx, messID, adminID, domain.AuditReinstate, targetUserID, &before, member)
+	})
+}
+
+// targetMember checks that an admin acts on another member of the mess and
+// returns the freshly loaded mess with that member's entry.
+func (s *MessService) targetMember(ctx context.Context, messID, targetUserID, adminID, action string) (*domain.Mess, *domain.Member, error) {
+	if err := authorize(ctx, s.repo, messID, adminID, accessAdmin, action); err != nil {
+		return nil, nil, err
+	}
+	if targetUserID == adminID {
+		return nil, nil, errors.New("admins cannot act on their own membership. Leave the mess instead")
+	}
+	mess, err := s.repo.GetByID(ctx, messID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if mess == nil {
+		return nil, nil, notFound("mess")
+	}
+	member := mess.FindMember(targetUserID)
+	if member == nil {
+		return nil, nil, notFound("member")
+	}
+	return mess, member, nil
+}
+
+// --- Audit ---
+
+// recordMember appends an audit entry for a change to the user's membership
+// entry. before or after is nil when the entry did not or no longer exists.
+func (s *MessService) recordMember(ctx context.Context, messID, actorID string, action domain.AuditAction, userID string, before, after *domain.Member) error {
+	// A nil *Member must reach Record as a plain nil, or it is logged as null
+	var b, a interface{}
+	if before != nil {
+		b = before
+	}
+	if after != nil {
+		a = after
+	}
+	return s.audit.Record(ctx, messID, monthOf(time.Now()), actorID, action, domain.AuditEntityMember, userID, b, a)
+}
+
+// recordRoleChanges records an update for every member, other than skip,
+// whose roles differ between before and after. Granting the manager role
+// takes it from whoever held it, and that is logged here too.
+func (s *MessService) recordRoleChanges(ctx context.Context, messID, actorID, skip string, before, after []domain.Member) error {
+	for i := range after {
+		m := &after[i]
+		if m.UserID == skip {
+			continue
+		}
+		for j := range before {
+			prev := &before[j]
+			if prev.UserID != m.UserID || slices.Equal(prev.Roles, m.Roles) {
+				continue
+			}
+			if err := s.recordMember(ctx, messID, actorID, domain.AuditUpdate, m.UserID, prev, m); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// recordMess appends an audit entry for a change to the mess itself, such
+// as its owner.
+func (s *MessService) recordMess(ctx context.Context, actorID string, action domain.AuditAction, before, after *domain.Mess) error {
+	return s.audit.Record(ctx, after.ID, monthOf(time.Now()), actorID, action, domain.AuditEntityMess, after.ID, before, after)
+}
+
+// cloneMember copies a member entry so later changes to the original, such
+// as ending a suspension, do not show through.
+func cloneMember(m domain.Member) domain.Member {
+	m.Roles = slices.Clone(m.Roles)
+	m.Suspensions = slices.Clone(m.Suspensions)
+	return m
+}
+
+func cloneMembers(members []domain.Member) []domain.Member {
+	cloned := make([]domain.Member, len(members))
+	for i, m := range members {
+		cloned[i] = cloneMember(m)
+	}
+	return cloned
+}
+
+// cloneMess copies the mess, with its members, as it is before a change.
+func cloneMess(mess *domain.Mess) *domain.Mess {
+	cloned := *mess
+	cloned.Members = cloneMembers(mess.Members)
+	return &cloned
+}
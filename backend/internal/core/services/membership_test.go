@@ -0,0 +1,146 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"context"
+	"errors"
+	"testing"
+)
+
+func newMembershipEnv() *testEnv {
+	return newTestEnv(member("a", domain.RoleAdmin), member("m", domain.RoleManager), member("b"))
+}
+
+func TestSuspendMember(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		target, actor string
+	}{
+		{name: "members cannot suspend", target: "b", actor: "m"},
+		{name: "admins cannot suspend themselves", target: "a", actor: "a"},
+		{name: "the only manager", target: "m", actor: "a"},
+		{name: "unknown member", target: "x", actor: "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newMembershipEnv()
+			if err := env.mess.SuspendMember(ctx, env.messID, tt.target, tt.actor, ""); err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, m := range env.messes.messes[env.messID].Members {
+				if m.Status != "active" || len(m.Suspensions) != 0 {
+					t.Errorf("%s: got status %s with %d suspensions after a refused suspension", m.UserID, m.Status, len(m.Suspensions))
+				}
+			}
+		})
+	}
+
+	t.Run("suspends and reinstates", func(t *testing.T) {
+		env := newMembershipEnv()
+		if err := env.mess.SuspendMember(ctx, env.messID, "b", "a", " away "); err != nil {
+			t.Fatal(err)
+		}
+		b := env.messes.messes[env.messID].FindMember("b")
+		if b.Status != "suspended" || len(b.Suspensions) != 1 || b.Suspensions[0].Until != nil || b.Suspensions[0].Reason != "away" {
+			t.Fatalf("got %+v, want one running suspension", b)
+		}
+		if err := env.mess.SuspendMember(ctx, env.messID, "b", "a", ""); err == nil {
+			t.Error("expected a second suspension to be refused")
+		}
+
+		if err := env.mess.ReinstateMember(ctx, env.messID, "b", "a"); err != nil {
+			t.Fatal(err)
+		}
+		b = env.messes.messes[env.messID].FindMember("b")
+		if b.Status != "active" || len(b.Suspensions) != 1 || b.Suspensions[0].Until == nil {
+			t.Errorf("got %+v, want active with the suspension ended", b)
+		}
+		if err := env.mess.ReinstateMember(ctx, env.messID, "b", "a"); err == nil {
+			t.Error("expected reinstating an active member to be refused")
+		}
+	})
+}
+
+func TestRemoveMember(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("refusals", func(t *testing.T) {
+		env := newMembershipEnv()
+		if err := env.mess.RemoveMember(ctx, env.messID, "b", "m"); err == nil {
+			t.Error("expected members to be refused")
+		}
+		if err := env.mess.RemoveMember(ctx, env.messID, "m", "a"); err == nil {
+			t.Error("expected removing the only manager to be refused")
+		}
+		if err := env.mess.RemoveMember(ctx, env.messID, "x", "a"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("got %v for an unknown member, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("removes a suspended member", func(t *testing.T) {
+		env := newMembershipEnv()
+		if err := env.mess.SuspendMember(ctx, env.messID, "b", "a", ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := env.mess.RemoveMember(ctx, env.messID, "b", "a"); err != nil {
+			t.Fatal(err)
+		}
+
+		b := env.messes.messes[env.messID].FindMember("b")
+		if b.Status != "left" || b.LeftAt == nil || b.Suspensions[0].Until == nil {
+			t.Errorf("got %+v, want left with the suspension ended", b)
+		}
+		user := env.users.users["b"]
+		if len(user.Messes) != 0 || user.CurrentMessID != "" {
+			t.Errorf("got messes %v and current %q, want the mess taken off the user", user.Messes, user.CurrentMessID)
+		}
+		if err := env.mess.RemoveMember(ctx, env.messID, "b", "a"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("got %v removing them again, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestGetFinalSettlement(t *testing.T) {
+	ctx := context.Background()
+	env := newSettlementEnv()
+
+	if _, err := env.finance.GetFinalSettlement(ctx, env.messID, "b", "a"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("got %v for a member who has not left, want ErrNotFound", err)
+	}
+
+	mess := env.messes.messes[env.messID]
+	b := mess.FindMember("b")
+	b.Status = "left"
+	leftAt := testJoined.AddDate(0, 5, 20) // 21 June
+	b.LeftAt = &leftAt
+
+	if _, err := env.finance.GetFinalSettlement(ctx, env.messID, "b", "b"); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("got %v for the departed member themselves, want ErrForbidden", err)
+	}
+	final, err := env.finance.GetFinalSettlement(ctx, env.messID, "b", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if final.Month != testMonth || final.Summary.DaysPresent != 21 {
+		t.Errorf("got %s with %d days, want %s with 21", final.Month, final.Summary.DaysPresent, testMonth)
+	}
+	// b still owes for every meal; the rent is now shared by days present
+	if final.Summary.MealCost != 400 || final.Summary.ClosingBalance >= 0 {
+		t.Errorf("got %+v, want b to owe for 400 of meals", final.Summary)
+	}
+
+	// Once the month is closed its snapshot is read, not the live records
+	if _, err := env.finance.CloseMonth(ctx, env.messID, testMonth, "a"); err != nil {
+		t.Fatal(err)
+	}
+	env.repo.bazars[0].Amount = 900
+	closed, err := env.finance.GetFinalSettlement(ctx, env.messID, "b", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if closed.Summary != final.Summary {
+		t.Errorf("got %+v from the closed month, want the snapshot %+v", closed.Summary, final.Summary)
+	}
+}
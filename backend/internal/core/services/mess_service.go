@@ -6,16 +6,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 )
 
 type MessService struct {
 	repo     domain.MessRepository
 	userRepo domain.UserRepository
+	invites  domain.InviteRepository
+	finance  *FinanceService
+	tx       domain.Transactor
+	audit    *AuditService
 }
 
-func NewMessService(repo domain.MessRepository, userRepo domain.UserRepository) *MessService {
-	return &MessService{repo: repo, userRepo: userRepo}
+func NewMessService(repo domain.MessRepository, userRepo domain.UserRepository, invites domain.InviteRepository, finance *FinanceService, tx domain.Transactor, audit *AuditService) *MessService {
+	return &MessService{repo: repo, userRepo: userRepo, invites: invites, finance: finance, tx: tx, audit: audit}
 }
 
 func (s *MessService) GetMessDetails(ctx context.Context, id string) (*domain.Mess, error) {
@@ -40,12 +45,6 @@ func (s *MessService) GetByID(ctx context.Context, id string) (*domain.Mess, err
 }
 
 func (s *MessService) CreateMess(ctx context.Context, name, adminID string) (*domain.Mess, error) {
-	// Check if user is already in a mess
-	existingUser, _ := s.userRepo.GetByID(ctx, adminID)
-	if existingUser != nil && len(existingUser.Messes) > 0 {
-		return nil, errors.New("you are already a member of a mess. leave it first")
-	}
-
 	// Generate Mess ID
 	id := utils.GenerateID(name, 4)
 
@@ -72,33 +71,32 @@ func (s *MessService) CreateMess(ctx context.Context, name, adminID string) (*do
 		CreatedAt: time.Now(),
 	}
 
-	if err := s.repo.Create(ctx, mess); err != nil {
+	// The creator switches to the new mess
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, mess); err != nil {
+			return err
+		}
+		if err := s.userRepo.AddMess(ctx, adminID, id); err != nil {
+			return err
+		}
+		return s.userRepo.SetCurrentMess(ctx, adminID, id)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// Update User's Mess List
-	user, err := s.userRepo.GetByID(ctx, adminID)
-	if err == nil && user != nil {
-		user.Messes = append(user.Messes, id)
-		user.CurrentMessID = id
-		s.userRepo.Update(ctx, user)
-	}
-
 	return mess, nil
 }
 
 func (s *MessService) RequestJoin(ctx context.Context, messID, userID string) error {
-	// Check if user is already in a mess
-	existingUser, _ := s.userRepo.GetByID(ctx, userID)
-	if existingUser != nil && len(existingUser.Messes) > 0 {
-		return errors.New("cannot join a new mess while being a member of another")
-	}
-
 	mess, err := s.repo.GetByID(ctx, messID)
 	if err != nil || mess == nil {
 		fmt.Printf("[DEBUG] RequestJoin: mess not found: %s\n", messID)
 		return errors.New("mess not found")
 	}
+	if mess.IsDissolved() {
+		return errMessDissolved
+	}
 	fmt.Printf("[DEBUG] RequestJoin: found mess: %s for user %s\n", mess.Name, userID)
 
 	// Check if already a member or pending
@@ -151,6 +149,7 @@ func (s *MessService) RequestJoin(ctx context.Context, messID, userID string) er
 	user, err = s.userRepo.GetByID(ctx, userID)
 	if err == nil && user != nil {
 		user.JoinRequests = append(user.JoinRequests, messID)
+		user.JoinRejections = withoutRejection(user.JoinRejections, messID)
 		if err := s.userRepo.Update(ctx, user); err != nil {
 			fmt.Printf("[DEBUG] RequestJoin: userRepo.Update failed: %v\n", err)
 		} else {
@@ -183,11 +182,20 @@ func (s *MessService) ApproveMember(ctx context.Context, messID, userID, approve
 		return errors.New("only admin can approve members")
 	}
 
+	// Only a pending request can be approved; anyone else is already in or
+	// was removed, suspended or left, and approving must not undo that
+	request := mess.FindMember(userID)
+	if request == nil || request.Status != "pending" {
+		return notFound("join request")
+	}
+	before := cloneMember(*request)
+
 	updatedMembers := []domain.Member{}
 	found := false
 	for _, m := range mess.Members {
 		if m.UserID == userID {
 			m.Status = "active"
+			m.JoinedAt = time.Now() // Membership, and cost sharing, starts on approval
 			// Ensure name is populated if it was missing
 			if m.Name == "" {
 				user, _ := s.userRepo.GetByID(ctx, userID)
@@ -205,43 +213,22 @@ func (s *MessService) ApproveMember(ctx context.Context, messID, userID, approve
 	}
 
 	mess.Members = updatedMembers
-	if err := s.repo.Update(ctx, mess); err != nil {
-		return err
-	}
 
-	// Update the target user's document
-	targetUser, err := s.userRepo.GetByID(ctx, userID)
-	if err == nil && targetUser != nil {
-		// Remove from JoinRequests
-		var newJoinRequests []string
-		for _, reqID := range targetUser.JoinRequests {
-			if reqID != messID {
-				newJoinRequests = append(newJoinRequests, reqID)
-			}
+	// The mess and the user document change together or not at all
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, mess); err != nil {
+			return err
 		}
-		targetUser.JoinRequests = newJoinRequests
 
-		// Add to Messes if not already there
-		isAlreadyInList := false
-		for _, mID := range targetUser.Messes {
-			if mID == messID {
-				isAlreadyInList = true
-				break
-			}
-		}
-		if !isAlreadyInList {
-			targetUser.Messes = append(targetUser.Messes, messID)
+		// Move the request into the user's messes, current if none is selected
+		if err := s.userRepo.AddMess(ctx, userID, messID); err != nil {
+			return err
 		}
-
-		// Set as current mess if none selected
-		if targetUser.CurrentMessID == "" {
-			targetUser.CurrentMessID = messID
+		if err := s.userRepo.SetCurrentMessIfUnset(ctx, userID, messID); err != nil {
+			return err
 		}
-
-		return s.userRepo.Update(ctx, targetUser)
-	}
-
-	return nil
+		return s.recordMember(ctx, messID, approverID, domain.AuditApprove, userID, &before, mess.FindMember(userID))
+	})
 }
 
 func (s *MessService) GetRequests(ctx context.Context, messID, userID string) ([]domain.Member, error) {
@@ -299,6 +286,7 @@ func (s *MessService) AssignRole(ctx context.Context, messID, targetUserID, admi
 	}
 
 	// Update Role
+	before := cloneMembers(mess.Members)
 	found := false
 	updatedMembers := []domain.Member{}
 	for _, m := range mess.Members {
@@ -337,7 +325,12 @@ func (s *MessService) AssignRole(ctx context.Context, messID, targetUserID, admi
 	}
 
 	mess.Members = updatedMembers
-	return s.repo.Update(ctx, mess)
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, mess); err != nil {
+			return err
+		}
+		return s.recordRoleChanges(ctx, messID, adminID, "", before, mess.Members)
+	})
 }
 
 func (s *MessService) RemoveRole(ctx context.Context, messID, targetUserID, adminID string, role domain.Role) error {
@@ -358,6 +351,10 @@ func (s *MessService) RemoveRole(ctx context.Context, messID, targetUserID, admi
 		return errors.New("unauthorized")
 	}
 
+	if role == domain.RoleAdmin && targetUserID == mess.AdminID {
+		return errors.New("the owner keeps the admin role. Transfer ownership first")
+	}
+
 	// Safety check: Prevent removing last admin/manager if others present
 	if role == domain.RoleAdmin || role == domain.RoleManager {
 		activeCount := 0
@@ -380,6 +377,7 @@ func (s *MessService) RemoveRole(ctx context.Context, messID, targetUserID, admi
 	}
 
 	// Update Role
+	before := cloneMembers(mess.Members)
 	found := false
 	updatedMembers := []domain.Member{}
 	for _, m := range mess.Members {
@@ -401,7 +399,12 @@ func (s *MessService) RemoveRole(ctx context.Context, messID, targetUserID, admi
 	}
 
 	mess.Members = updatedMembers
-	return s.repo.Update(ctx, mess)
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, mess); err != nil {
+			return err
+		}
+		return s.recordRoleChanges(ctx, messID, adminID, "", before, mess.Members)
+	})
 }
 
 func (s *MessService) LeaveMess(ctx context.Context, messID, userID string) error {
@@ -416,79 +419,128 @@ func (s *MessService) LeaveMess(ctx context.Context, messID, userID string) erro
 	}
 
 	// 1. Find the member in the mess
-	var leavingMember *domain.Member
-	activeMemberCount := 0
-	for i, m := range mess.Members {
-		if m.Status == "active" {
-			activeMemberCount++
-			if m.UserID == userID {
-				leavingMember = &mess.Members[i]
-			}
-		}
-	}
-
-	if leavingMember == nil {
+	leavingMember := mess.FindMember(userID)
+	if leavingMember == nil || leavingMember.Status != "active" {
 		return errors.New("you are not an active member of this mess")
 	}
 
 	// 2. If others are present, enforce role safety
-	if activeMemberCount > 1 {
-		hasOtherAdmin := false
-		hasOtherManager := false
+	switch lastRoleHolder(mess, userID) {
+	case domain.RoleAdmin:
+		return errors.New("you are the only admin. Please assign another member as admin before leaving")
+	case domain.RoleManager:
+		return errors.New("you are the only manager. Please assign another member as manager before leaving")
+	}
 
-		for _, m := range mess.Members {
-			if m.UserID != userID && m.Status == "active" {
-				for _, r := range m.Roles {
-					if r == domain.RoleAdmin {
-						hasOtherAdmin = true
-					}
-					if r == domain.RoleManager {
-						hasOtherManager = true
-					}
-				}
-			}
-		}
+	// 3. Mark as left in mess and update the user document
+	return s.departMember(ctx, mess, user, userID, domain.AuditLeave)
+}
 
-		// Check Admin Role
-		isLeavingAdmin := false
-		for _, r := range leavingMember.Roles {
-			if r == domain.RoleAdmin {
-				isLeavingAdmin = true
-				break
-			}
-		}
+// SwitchCurrentMess makes the mess the user's current one, which is used
+// where no mess is named, such as feed posts.
+func (s *MessService) SwitchCurrentMess(ctx context.Context, messID, userID string) error {
+	// Suspended members still see the mess on their profile, so they can
+	// switch to it too
+	if err := authorize(ctx, s.repo, messID, userID, accessReader, "switch to this mess"); err != nil {
+		return err
+	}
+	mess, err := loadMess(ctx, s.repo, messID)
+	if err != nil {
+		return err
+	}
+	if mess.IsDissolved() {
+		return errMessDissolved
+	}
+	return s.userRepo.SetCurrentMess(ctx, userID, messID)
+}
+
+// lastRoleHolder returns the admin or manager role that would be left
+// without an active holder if the user stopped being active, or "" if none
+// would. A member alone in the mess may always go.
+func lastRoleHolder(mess *domain.Mess, userID string) domain.Role {
+	member := mess.FindMember(userID)
+	if member == nil || member.Status != "active" {
+		return ""
+	}
 
-		if isLeavingAdmin && !hasOtherAdmin {
-			return errors.New("you are the only admin. Please assign another member as admin before leaving")
+	activeMemberCount := 0
+	hasOtherAdmin := false
+	hasOtherManager := false
+	for _, m := range mess.Members {
+		if m.Status != "active" {
+			continue
 		}
+		activeMemberCount++
+		if m.UserID != userID {
+			hasOtherAdmin = hasOtherAdmin || m.HasRole(domain.RoleAdmin)
+			hasOtherManager = hasOtherManager || m.HasRole(domain.RoleManager)
+		}
+	}
+	if activeMemberCount <= 1 {
+		return ""
+	}
 
-		// Check Manager Role
-		isLeavingManager := false
-		for _, r := range leavingMember.Roles {
-			if r == domain.RoleManager {
-				isLeavingManager = true
-				break
-			}
+	if member.HasRole(domain.RoleAdmin) && !hasOtherAdmin {
+		return domain.RoleAdmin
+	}
+	if member.HasRole(domain.RoleManager) && !hasOtherManager {
+		return domain.RoleManager
+	}
+	return ""
+}
+
+// departMember marks the user as having left the mess and takes the mess
+// off their account. The audit log records the action by the actor.
+func (s *MessService) departMember(ctx context.Context, mess *domain.Mess, user *domain.User, actorID string, action domain.AuditAction) error {
+	previous := cloneMess(mess)
+	before := cloneMember(*mess.FindMember(user.ID))
+	markLeft(mess, user.ID, time.Now())
+	dropMess(user, mess.ID)
+
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, mess); err != nil {
+			return err
+		}
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		if err := s.recordMember(ctx, mess.ID, actorID, action, user.ID, &before, mess.FindMember(user.ID)); err != nil {
+			return err
+		}
+		// Ownership passed on with them
+		if mess.AdminID != previous.AdminID {
+			return s.recordMess(ctx, actorID, domain.AuditUpdate, previous, mess)
 		}
+		return nil
+	})
+}
 
-		if isLeavingManager && !hasOtherManager {
-			return errors.New("you are the only manager. Please assign another member as manager before leaving")
+// markLeft ends the user's membership, and any running suspension, at the
+// given time. If they owned the mess, ownership passes to another active
+// admin so AdminID always names an admin.
+func markLeft(mess *domain.Mess, userID string, leftAt time.Time) {
+	member := mess.FindMember(userID)
+	member.Status = "left"
+	member.LeftAt = &leftAt
+	for i := range member.Suspensions {
+		if member.Suspensions[i].Until == nil {
+			member.Suspensions[i].Until = &leftAt
 		}
 	}
 
-	// 3. Mark as left in mess
-	for i, m := range mess.Members {
-		if m.UserID == userID {
-			mess.Members[i].Status = "left"
-			break
+	if mess.AdminID == userID {
+		if next := mess.ActiveAdminOtherThan(userID); next != nil {
+			mess.AdminID = next.UserID
 		}
 	}
-
-	if err := s.repo.Update(ctx, mess); err != nil {
-		return err
+	if t := mess.PendingTransfer; t != nil && (t.ToUserID == userID || t.RequestedBy == userID) {
+		mess.PendingTransfer = nil
 	}
+}
 
-	// 4. Update User document
+// dropMess takes the mess off the user's account, picking another current
+// mess if it was the current one.
+func dropMess(user *domain.User, messID string) {
 	var updatedMesses []string
 	for _, mID := range user.Messes {
 		if mID != messID {
@@ -503,6 +555,68 @@ func (s *MessService) LeaveMess(ctx context.Context, messID, userID string) erro
 			user.CurrentMessID = ""
 		}
 	}
+}
+
+// GetMealConfig returns the meal slots and weights used for the meal rate.
+func (s *MessService) GetMealConfig(ctx context.Context, messID, userID string) (*domain.MealConfig, error) {
+	if !isActiveMember(ctx, s.repo, messID, userID) {
+		return nil, errors.New("only members can view the meal configuration")
+	}
+	mess, err := s.repo.GetByID(ctx, messID)
+	if err != nil || mess == nil {
+		return nil, errors.New("mess not found")
+	}
+	config := mess.MealSlotsConfig()
+	return &config, nil
+}
+
+// UpdateMealConfig replaces the mess's meal configuration. Open months are
+// recalculated with it; closed months keep their snapshots.
+func (s *MessService) UpdateMealConfig(ctx context.Context, messID, userID string, config domain.MealConfig) error {
+	if !isMessManager(ctx, s.repo, messID, userID) {
+		return errors.New("only manager or admin can change the meal configuration")
+	}
+
+	if len(config.Slots) == 0 {
+		return errors.New("at least one meal slot is required")
+	}
+	seen := make(map[string]bool)
+	for i := range config.Slots {
+		slot := &config.Slots[i]
+		slot.Key = strings.ToLower(strings.TrimSpace(slot.Key))
+		slot.Name = strings.TrimSpace(slot.Name)
+		if slot.Key == "" {
+			return fmt.Errorf("slot %d: key is required", i+1)
+		}
+		if seen[slot.Key] {
+			return fmt.Errorf("duplicate meal slot %q", slot.Key)
+		}
+		seen[slot.Key] = true
+		if slot.Name == "" {
+			slot.Name = slot.Key
+		}
+		if slot.Weight <= 0 {
+			return fmt.Errorf("meal slot %q: weight must be positive", slot.Key)
+		}
+	}
+	if config.GuestWeight < 0 || config.GuestPrice < 0 {
+		return errors.New("guest weight and price cannot be negative")
+	}
+	if config.CutoffTime != "" {
+		if _, err := time.Parse("15:04", config.CutoffTime); err != nil {
+			return errors.New("cutoff time must be in HH:MM format")
+		}
+	}
 
-	return s.userRepo.Update(ctx, user)
+	mess, err := s.repo.GetByID(ctx, messID)
+	if err != nil || mess == nil {
+		return errors.New("mess not found")
+	}
+	before := mess.MealSlotsConfig()
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateMealConfig(ctx, messID, config); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, messID, time.Now().Format("2006-01"), userID, domain.AuditUpdate, domain.AuditEntityMealConfig, messID, before, config)
+	})
 }
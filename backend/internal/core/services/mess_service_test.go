@@ -0,0 +1,141 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// newMultiMessEnv adds a second mess, MESS-2, that a and b also belong to,
+// and a third, MESS-3, where a is only waiting for approval.
+func newMultiMessEnv() *testEnv {
+	env := newTestEnv(member("a", domain.RoleAdmin), member("b"))
+	pending := member("a")
+	pending.Status = "pending"
+	env.messes.messes["MESS-2"] = &domain.Mess{ID: "MESS-2", Members: []domain.Member{member("a"), member("b", domain.RoleAdmin)}}
+	env.messes.messes["MESS-3"] = &domain.Mess{ID: "MESS-3", Members: []domain.Member{pending, member("c", domain.RoleAdmin)}}
+	for _, userID := range []string{"a", "b"} {
+		env.users.users[userID].Messes = []string{env.messID, "MESS-2"}
+	}
+	return env
+}
+
+func TestSwitchCurrentMess(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		messID string
+	}{
+		{name: "mess the user is waiting to join", messID: "MESS-3"},
+		{name: "unknown mess", messID: "MESS-9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newMultiMessEnv()
+			if err := env.mess.SwitchCurrentMess(ctx, tt.messID, "a"); !errors.Is(err, domain.ErrForbidden) {
+				t.Fatalf("got %v, want ErrForbidden", err)
+			}
+			user := env.users.users["a"]
+			if user.CurrentMessID != env.messID || slices.Contains(user.Messes, tt.messID) {
+				t.Errorf("a refused switch changed the user to %q with messes %v", user.CurrentMessID, user.Messes)
+			}
+		})
+	}
+
+	t.Run("dissolved mess", func(t *testing.T) {
+		env := newMultiMessEnv()
+		dissolvedAt := testJoined
+		env.messes.messes["MESS-2"].DissolvedAt = &dissolvedAt
+		if err := env.mess.SwitchCurrentMess(ctx, "MESS-2", "a"); err == nil {
+			t.Fatal("expected an error")
+		}
+		if current := env.users.users["a"].CurrentMessID; current != env.messID {
+			t.Errorf("a refused switch changed the current mess to %q", current)
+		}
+	})
+
+	t.Run("suspended members can switch", func(t *testing.T) {
+		env := newMultiMessEnv()
+		env.messes.messes["MESS-2"].FindMember("a").Status = "suspended"
+		if err := env.mess.SwitchCurrentMess(ctx, "MESS-2", "a"); err != nil {
+			t.Fatal(err)
+		}
+		if current := env.users.users["a"].CurrentMessID; current != "MESS-2" {
+			t.Errorf("got current %q, want MESS-2", current)
+		}
+	})
+
+	t.Run("switches between the user's messes", func(t *testing.T) {
+		env := newMultiMessEnv()
+		if err := env.mess.SwitchCurrentMess(ctx, "MESS-2", "a"); err != nil {
+			t.Fatal(err)
+		}
+		user := env.users.users["a"]
+		if user.CurrentMessID != "MESS-2" || len(user.Messes) != 2 {
+			t.Errorf("got current %q with messes %v, want MESS-2 and the same two messes", user.CurrentMessID, user.Messes)
+		}
+	})
+}
+
+func TestDropMess(t *testing.T) {
+	tests := []struct {
+		name        string
+		messes      []string
+		current     string
+		drop        string
+		wantMesses  []string
+		wantCurrent string
+	}{
+		{
+			name:        "current mess moves to the next one",
+			messes:      []string{"M1", "M2", "M3"},
+			current:     "M1",
+			drop:        "M1",
+			wantMesses:  []string{"M2", "M3"},
+			wantCurrent: "M2",
+		},
+		{
+			name:        "other messes keep the current one",
+			messes:      []string{"M1", "M2", "M3"},
+			current:     "M3",
+			drop:        "M2",
+			wantMesses:  []string{"M1", "M3"},
+			wantCurrent: "M3",
+		},
+		{
+			name:        "last mess clears the current one",
+			messes:      []string{"M1"},
+			current:     "M1",
+			drop:        "M1",
+			wantMesses:  nil,
+			wantCurrent: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &domain.User{Messes: tt.messes, CurrentMessID: tt.current}
+			dropMess(user, tt.drop)
+			if !slices.Equal(user.Messes, tt.wantMesses) || user.CurrentMessID != tt.wantCurrent {
+				t.Errorf("got %v current %q, want %v current %q", user.Messes, user.CurrentMessID, tt.wantMesses, tt.wantCurrent)
+			}
+		})
+	}
+}
+
+func TestLeaveMessPicksNextCurrentMess(t *testing.T) {
+	env := newMultiMessEnv()
+	if err := env.mess.LeaveMess(context.Background(), env.messID, "b"); err != nil {
+		t.Fatal(err)
+	}
+	user := env.users.users["b"]
+	if user.CurrentMessID != "MESS-2" || !slices.Equal(user.Messes, []string{"MESS-2"}) {
+		t.Errorf("got current %q with messes %v, want MESS-2 only", user.CurrentMessID, user.Messes)
+	}
+	if b := env.messes.messes[env.messID].FindMember("b"); b.Status != "left" {
+		t.Errorf("got status %s, want left", b.Status)
+	}
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// --- Ownership and Dissolution ---
+
+var errMessDissolved = errors.New("this mess has been dissolved")
+
+// TransferOwnership offers the mess to another active member. It takes
+// effect once they accept. Only the owner can offer it, unless the owner is
+// no longer an active admin, in which case any admin can.
+func (s *MessService) TransferOwnership(ctx context.Context, messID, toUserID, userID string) error {
+	mess, err := s.ownedMess(ctx, messID, userID, "transfer ownership")
+	if err != nil {
+		return err
+	}
+	if toUserID == mess.AdminID {
+		return errors.New("this member already owns the mess")
+	}
+	if target := mess.FindMember(toUserID); target == nil || target.Status != "active" {
+		return errors.New("ownership can only go to an active member")
+	}
+
+	before := cloneMess(mess)
+	mess.PendingTransfer = &domain.OwnershipTransfer{
+		ToUserID:    toUserID,
+		RequestedBy: userID,
+		RequestedAt: time.Now(),
+	}
+	return s.updateMess(ctx, userID, before, mess)
+}
+
+// AcceptOwnership makes the user the owner of the mess, granting them the
+// admin role. The previous owner stays an admin.
+func (s *MessService) AcceptOwnership(ctx context.Context, messID, userID string) error {
+	mess, err := s.repo.GetByID(ctx, messID)
+	if err != nil {
+		return err
+	}
+	if mess == nil {
+		return notFound("mess")
+	}
+	if mess.PendingTransfer == nil || mess.PendingTransfer.ToUserID != userID {
+		return notFound("ownership transfer")
+	}
+	member := mess.FindMember(userID)
+	if member == nil || member.Status != "active" {
+		return errors.New("you are not an active member of this mess")
+	}
+
+	before := cloneMess(mess)
+	if !member.HasRole(domain.RoleAdmin) {
+		member.Roles = append(member.Roles, domain.RoleAdmin)
+	}
+	mess.AdminID = userID
+	mess.PendingTransfer = nil
+	return s.updateMess(ctx, userID, before, mess)
+}
+
+// CancelOwnershipTransfer withdraws a pending transfer. The member it was
+// offered to can decline it, and any admin can withdraw it.
+func (s *MessService) CancelOwnershipTransfer(ctx context.Context, messID, userID string) error {
+	mess, err := s.repo.GetByID(ctx, messID)
+	if err != nil {
+		return err
+	}
+	if mess == nil {
+		return notFound("mess")
+	}
+	if mess.PendingTransfer == nil {
+		return notFound("ownership transfer")
+	}
+	if mess.PendingTransfer.ToUserID != userID {
+		if err := authorize(ctx, s.repo, messID, userID, accessAdmin, "cancel ownership transfers"); err != nil {
+			return err
+		}
+	}
+
+	before := cloneMess(mess)
+	mess.PendingTransfer = nil
+	return s.updateMess(ctx, userID, before, mess)
+}
+
+// DissolveMess winds the mess up: every month not closed yet is closed in
+// order up to the current one, everyone is marked as left and the mess is
+// taken off their accounts. Pending join requests and invites stop working.
+// The books stay readable to former members, and its managers can still
+// record the settlement. It returns the settlement plan for the current
+// month, which is what members still owe each other.
+func (s *MessService) DissolveMess(ctx context.Context, messID, userID string) (*domain.SettlementPlan, error) {
+	mess, err := s.ownedMess(ctx, messID, userID, "dissolve the mess")
+	if err != nil {
+		return nil, err
+	}
+
+	months, err := s.finance.monthsToClose(ctx, mess)
+	if err != nil {
+		return nil, err
+	}
+	for _, month := range months {
+		if _, err := s.finance.CloseMonth(ctx, messID, month, userID); err != nil {
+			return nil, fmt.Errorf("closing %s: %w", month, err)
+		}
+	}
+	plan, err := s.finance.GetSettlementPlan(ctx, messID, months[len(months)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	// Reload: closing the months may have taken a while
+	mess, err = s.repo.GetByID(ctx, messID)
+	if err != nil {
+		return nil, err
+	}
+	if mess == nil {
+		return nil, notFound("mess")
+	}
+
+	previous := cloneMess(mess)
+	now := time.Now()
+	owner := mess.AdminID
+	var users []*domain.User
+	var members []domain.Member
+	for _, m := range mess.Members {
+		if m.Status == "left" {
+			members = append(members, m)
+			continue
+		}
+		if m.Status != "pending" {
+			markLeft(mess, m.UserID, now)
+			members = append(members, *mess.FindMember(m.UserID))
+		}
+
+		user, err := s.userRepo.GetByID(ctx, m.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			continue
+		}
+		dropMess(user, messID)
+		var joinRequests []string
+		for _, reqID := range user.JoinRequests {
+			if reqID != messID {
+				joinRequests = append(joinRequests, reqID)
+			}
+		}
+		user.JoinRequests = joinRequests
+		users = append(users, user)
+	}
+	// Pending requests are dropped rather than marked as left. The owner
+	// stays on record even though everyone left.
+	mess.Members = members
+	mess.AdminID = owner
+	mess.PendingTransfer = nil
+	mess.DissolvedAt = &now
+
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, mess); err != nil {
+			return err
+		}
+		for _, user := range users {
+			if err := s.userRepo.Update(ctx, user); err != nil {
+				return err
+			}
+		}
+		return s.recordMess(ctx, userID, domain.AuditDissolve, previous, mess)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// updateMess saves an ownership change to the mess and records it.
+func (s *MessService) updateMess(ctx context.Context, actorID string, before, after *domain.Mess) error {
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, after); err != nil {
+			return err
+		}
+		return s.recordMess(ctx, actorID, domain.AuditUpdate, before, after)
+	})
+}
+
+// ownedMess loads the mess for an owner-only action. The owner may act, and
+// so may any admin once the owner is no longer an active admin.
+func (s *MessService) ownedMess(ctx context.Context, messID, userID, action string) (*domain.Mess, error) {
+	if err := authorize(ctx, s.repo, messID, userID, accessAdmin, action); err != nil {
+		return nil, err
+	}
+	mess, err := s.repo.GetByID(ctx, messID)
+	if err != nil {
+		return nil, err
+	}
+	if mess == nil {
+		return nil, notFound("mess")
+	}
+	if mess.IsDissolved() {
+		return nil, errMessDissolved
+	}
+	if mess.AdminID != userID {
+		if owner := mess.FindMember(mess.AdminID); owner != nil && owner.Status == "active" && owner.HasRole(domain.RoleAdmin) {
+			return nil, fmt.Errorf("%w: only the owner can %s", domain.ErrForbidden, action)
+		}
+	}
+	return mess, nil
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"amar-dera/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// settlementParty is a member (or the mess fund) with an outstanding balance.
+// Positive balances are owed money, negative balances owe money.
+type settlementParty struct {
+	ID      string
+	Name    string
+	Balance domain.Money
+}
+
+// planSettlement greedily matches the largest debtor with the largest creditor
+// until every balance is zero, which needs at most len(parties)-1 transfers.
+func planSettlement(parties []settlementParty) []domain.SettlementTransfer {
+	var debtors, creditors []settlementParty
+	for _, p := range parties {
+		if p.Balance < 0 {
+			debtors = append(debtors, p)
+		} else if p.Balance > 0 {
+			creditors = append(creditors, p)
+		}
+	}
+
+	transfers := []domain.SettlementTransfer{}
+	for len(debtors) > 0 && len(creditors) > 0 {
+		// Largest amounts first keeps the number of transfers small
+		sort.Slice(debtors, func(i, j int) bool { return debtors[i].Balance < debtors[j].Balance })
+		sort.Slice(creditors, func(i, j int) bool { return creditors[i].Balance > creditors[j].Balance })
+
+		d, c := &debtors[0], &creditors[0]
+		amount := min(-d.Balance, c.Balance)
+		if amount > 0 {
+			transfers = append(transfers, domain.SettlementTransfer{
+				FromID:   d.ID,
+				FromName: d.Name,
+				ToID:     c.ID,
+				ToName:   c.Name,
+				Amount:   amount,
+				Status:   "suggested",
+			})
+		}
+
+		d.Balance += amount
+		c.Balance -= amount
+		if d.Balance >= 0 {
+			debtors = debtors[1:]
+		}
+		if c.Balance <= 0 {
+			creditors = creditors[1:]
+		}
+	}
+	return transfers
+}
+
+// payinType books cash from a member against their larger deficit.
+func payinType(ms domain.MemberSummary) domain.PaymentType {
+	if ms.HouseBalance < ms.MealBalance {
+		return domain.PaymentTypeHouse
+	}
+	return domain.PaymentTypeMeal
+}
+
+// payoutType books cash to a member against their larger surplus.
+func payoutType(ms domain.MemberSummary) domain.PaymentType {
+	if ms.HouseBalance > ms.MealBalance {
+		return domain.PaymentTypeHouse
+	}
+	return domain.PaymentTypeMeal
+}
+
+// GetSettlementPlan suggests the transfers that zero every closing balance of
+// the month. The mess fund absorbs the difference between what members paid
+// and what was spent. Open months get a preview on their live balances;
+// transfers can only be recorded once the month is closed.
+func (s *FinanceService) GetSettlementPlan(ctx context.Context, messID, month string) (*domain.SettlementPlan, error) {
+	plan, _, err := s.settlementPlan(ctx, messID, month)
+	return plan, err
+}
+
+// settlementPlan returns the plan along with the summary it was drawn from.
+func (s *FinanceService) settlementPlan(ctx context.Context, messID, month string) (*domain.SettlementPlan, *domain.MonthSummary, error) {
+	summary, err := s.closedSummary(ctx, messID, month)
+	if err != nil {
+		return nil, nil, err
+	}
+	closed := summary != nil
+	if !closed {
+		if summary, err = s.GenerateMonthlySummary(ctx, messID, month); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	parties := []settlementParty{}
+	var memberTotal domain.Money
+	for _, ms := range summary.MemberSummaries {
+		parties = append(parties, settlementParty{ID: ms.UserID, Name: ms.Name, Balance: ms.ClosingBalance})
+		memberTotal += ms.ClosingBalance
+	}
+	// Cash held by the fund is what members paid beyond their share, so the
+	// fund owes it back and enters the plan with the opposite sign.
+	fundBalance := memberTotal
+	parties = append(parties, settlementParty{ID: domain.MessFundID, Name: "Mess Fund", Balance: -fundBalance})
+
+	transfers := planSettlement(parties)
+	for i := range transfers {
+		transfers[i].MessID = messID
+		transfers[i].Month = month
+	}
+
+	completed, err := s.repo.GetSettlementTransfers(ctx, messID, month)
+	if err != nil {
+		return nil, nil, err
+	}
+	if completed == nil {
+		completed = []domain.SettlementTransfer{}
+	}
+
+	return &domain.SettlementPlan{
+		Month:       month,
+		Closed:      closed,
+		FundBalance: fundBalance,
+		Transfers:   transfers,
+		Completed:   completed,
+	}, summary, nil
+}
+
+// closedSummary returns the frozen summary of a closed month, with each
+// member's balances reduced by what has been settled since in later months.
+// It returns nil while the month is open.
+func (s *FinanceService) closedSummary(ctx context.Context, messID, month string) (*domain.MonthSummary, error) {
+	lock, err := s.repo.GetMonthLock(ctx, messID, month)
+	if err != nil {
+		return nil, err
+	}
+	if !lock.IsActive(time.Now()) {
+		return nil, nil
+	}
+	snapshot, err := s.repo.GetLatestSummarySnapshot(ctx, messID, month)
+	if err != nil || snapshot == nil {
+		return nil, err
+	}
+
+	settled, err := s.repo.GetSettlingPayments(ctx, messID, month)
+	if err != nil {
+		return nil, err
+	}
+	summary := snapshot.Summary
+	summary.SnapshotVersion = snapshot.Version
+	if summary.MemberSummaries == nil {
+		summary.MemberSummaries = make(map[string]domain.MemberSummary)
+	}
+	for _, p := range settled {
+		if p.Status != "approved" {
+			continue
+		}
+		ms, ok := summary.MemberSummaries[p.UserID]
+		if !ok {
+			ms = domain.MemberSummary{UserID: p.UserID}
+		}
+		if p.Type == domain.PaymentTypeHouse {
+			ms.HouseBalance += p.Amount
+		} else {
+			ms.MealBalance += p.Amount
+		}
+		ms.ClosingBalance += p.Amount
+		summary.MemberSummaries[p.UserID] = ms
+	}
+	return &summary, nil
+}
+
+// settlementMonth returns the first open month after a closed one. Payments
+// settling the closed month are booked there, against the balances carried
+// into it, so the frozen month stays as it was closed.
+func (s *FinanceService) settlementMonth(ctx context.Context, messID, month string) (string, error) {
+	next := month
+	for {
+		var err error
+		if next, err = nextMonth(next); err != nil {
+			return "", err
+		}
+		err = s.checkMonthLock(ctx, messID, next)
+		if err == nil {
+			return next, nil
+		}
+		if !errors.Is(err, domain.ErrMonthLocked) {
+			return "", err
+		}
+	}
+}
+
+// CompleteSettlementTransfer marks a suggested transfer of a closed month as
+// done and records the matching payments in the next open month: a payment
+// in for the payer and a payout for the payee.
+func (s *FinanceService) CompleteSettlementTransfer(ctx context.Context, messID, month, fromID, toID string, amount domain.Money, userID string) (*domain.SettlementTransfer, error) {
+	if err := s.authorize(ctx, messID, userID, accessBookkeeper, "settle balances"); err != nil {
+		return nil, err
+	}
+
+	plan, summary, err := s.settlementPlan(ctx, messID, month)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.Closed {
+		return nil, fmt.Errorf("%w: close the month before settling it", domain.ErrConflict)
+	}
+
+	var suggested *domain.SettlementTransfer
+	for i, t := range plan.Transfers {
+		if t.FromID == fromID && t.ToID == toID {
+			suggested = &plan.Transfers[i]
+			break
+		}
+	}
+	if suggested == nil {
+		return nil, fmt.Errorf("%w: transfer is not part of the settlement plan", domain.ErrInvalid)
+	}
+	if amount <= 0 {
+		amount = suggested.Amount
+	}
+	if amount > suggested.Amount {
+		return nil, fmt.Errorf("%w: amount exceeds the suggested transfer", domain.ErrInvalid)
+	}
+
+	bookMonth, err := s.settlementMonth(ctx, messID, month)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := *suggested
+	transfer.ID = utils.GenerateID("SETL", 6)
+	transfer.Amount = amount
+	transfer.Status = "done"
+	transfer.CompletedBy = userID
+	transfer.CompletedAt = time.Now()
+
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		transfer.PaymentIDs = nil
+
+		// Payer hands over cash: counts as a payment in
+		if fromID != domain.MessFundID {
+			ms := summary.MemberSummaries[fromID]
+			id, err := s.recordSettlementPayment(ctx, messID, bookMonth, month, fromID, amount, payinType(ms), transfer.ID, userID)
+			if err != nil {
+				return err
+			}
+			transfer.PaymentIDs = append(transfer.PaymentIDs, id)
+		}
+
+		// Payee receives cash: recorded as a negative payment
+		if toID != domain.MessFundID {
+			ms := summary.MemberSummaries[toID]
+			id, err := s.recordSettlementPayment(ctx, messID, bookMonth, month, toID, -amount, payoutType(ms), transfer.ID, userID)
+			if err != nil {
+				return err
+			}
+			transfer.PaymentIDs = append(transfer.PaymentIDs, id)
+		}
+
+		if err := s.repo.CreateSettlementTransfer(ctx, &transfer); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, messID, month, userID, domain.AuditSettle, domain.AuditEntitySettlement, transfer.ID, nil, transfer)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (s *FinanceService) recordSettlementPayment(ctx context.Context, messID, month, settlesMonth, userID string, amount domain.Money, paymentType domain.PaymentType, settlementID, approverID string) (string, error) {
+	kind := domain.PaymentKindPayment
+	if amount < 0 {
+		kind = domain.PaymentKindRefund
+	}
+	payment := &domain.Payment{
+		ID:           utils.GenerateID("PAY", 4),
+		UserID:       userID,
+		MessID:       messID,
+		Amount:       amount,
+		Type:         paymentType,
+		Status:       "approved",
+		Month:        month,
+		CreatedAt:    time.Now(),
+		ApprovedBy:   approverID,
+		Kind:         kind,
+		SettlementID: settlementID,
+		SettlesMonth: settlesMonth,
+	}
+	if err := s.repo.CreatePayment(ctx, payment); err != nil {
+		return "", err
+	}
+	if err := s.ledger.PostPayment(ctx, payment, approverID); err != nil {
+		return "", err
+	}
+	return payment.ID, nil
+}
+
+// GetFinalSettlement shows where a departed member stands in the month they
+// left, so the mess can settle up with them. Only the member themselves and
+// managers can see it.
+func (s *FinanceService) GetFinalSettlement(ctx context.Context, messID, memberID, userID string) (*domain.FinalSettlement, error) {
+	if err := s.authorizeReader(ctx, messID, memberID, userID, "view final settlements"); err != nil {
+		return nil, err
+	}
+	mess, err := s.messRepo.GetByID(ctx, messID)
+	if err != nil || mess == nil {
+		return nil, notFound("mess")
+	}
+	member := mess.FindMember(memberID)
+	if member == nil || member.Status != "left" || member.LeftAt == nil {
+		return nil, notFound("departed member")
+	}
+
+	// A closed month is read from its snapshot, as the settlement plan is
+	month := member.LeftAt.Format("2006-01")
+	summary, err := s.closedSummary(ctx, messID, month)
+	if err != nil {
+		return nil, err
+	}
+	if summary == nil {
+		if summary, err = s.GenerateMonthlySummary(ctx, messID, month); err != nil {
+			return nil, err
+		}
+	}
+	ms, ok := summary.MemberSummaries[memberID]
+	if !ok {
+		ms = domain.MemberSummary{UserID: memberID, Name: member.Name}
+	}
+	return &domain.FinalSettlement{
+		UserID:  memberID,
+		Name:    ms.Name,
+		LeftAt:  *member.LeftAt,
+		Month:   month,
+		Summary: ms,
+	}, nil
+}
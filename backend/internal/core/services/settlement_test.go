@@ -0,0 +1,187 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPlanSettlement(t *testing.T) {
+	tests := []struct {
+		name    string
+		parties []settlementParty
+		want    []domain.SettlementTransfer
+	}{
+		{
+			name:    "nobody owes anything",
+			parties: []settlementParty{{ID: "a"}, {ID: "b"}},
+			want:    []domain.SettlementTransfer{},
+		},
+		{
+			name:    "one debtor pays one creditor",
+			parties: []settlementParty{{ID: "a", Balance: -150}, {ID: "b", Balance: 150}},
+			want:    []domain.SettlementTransfer{{FromID: "a", ToID: "b", Amount: 150}},
+		},
+		{
+			name: "largest debtor pays largest creditor first",
+			parties: []settlementParty{
+				{ID: "a", Balance: -100},
+				{ID: "b", Balance: -300},
+				{ID: "c", Balance: 250},
+				{ID: "d", Balance: 150},
+			},
+			want: []domain.SettlementTransfer{
+				{FromID: "b", ToID: "c", Amount: 250},
+				{FromID: "a", ToID: "d", Amount: 100},
+				{FromID: "b", ToID: "d", Amount: 50},
+			},
+		},
+		{
+			name: "the mess fund settles like a member",
+			parties: []settlementParty{
+				{ID: domain.MessFundID, Balance: 1050},
+				{ID: "a", Balance: -525},
+				{ID: "b", Balance: -525},
+			},
+			want: []domain.SettlementTransfer{
+				{FromID: "a", ToID: domain.MessFundID, Amount: 525},
+				{FromID: "b", ToID: domain.MessFundID, Amount: 525},
+			},
+		},
+		{
+			name:    "unbalanced books leave the excess unsettled",
+			parties: []settlementParty{{ID: "a", Balance: -200}, {ID: "b", Balance: 50}},
+			want:    []domain.SettlementTransfer{{FromID: "a", ToID: "b", Amount: 50}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkTransfers(t, planSettlement(tt.parties), tt.want)
+			for i, got := range planSettlement(tt.parties) {
+				if got.Status != "suggested" {
+					t.Errorf("transfer %d: got status %q, want suggested", i, got.Status)
+				}
+			}
+		})
+	}
+}
+
+// newSettlementEnv sets up a month where a bought the bazar and paid more
+// than their share, b paid less, and the fund still holds what a overpaid
+// towards the service cost:
+//
+//	a: pays 500, owes 150 service + 200 meals -> +150
+//	b: pays 100, owes 150 service + 400 meals -> -450
+//	fund: holds the 300 members are short -> owed 300
+func newSettlementEnv() *testEnv {
+	env := newTestEnv(member("a", domain.RoleManager), member("b"))
+	env.repo.bazars = []domain.Bazar{
+		{ID: "BZ-1", MessID: env.messID, BuyerID: "a", Amount: 600, Status: "approved", Month: testMonth},
+	}
+	env.repo.meals = []domain.DailyMeal{
+		{MessID: env.messID, UserID: "a", Month: testMonth, Breakfast: 1, Lunch: 1},
+		{MessID: env.messID, UserID: "b", Month: testMonth, Breakfast: 1, Lunch: 1, Dinner: 1, GuestMeals: 1},
+	}
+	env.repo.costs = []domain.ServiceCost{
+		{ID: "COST-1", MessID: env.messID, Month: testMonth, Name: "Rent", Amount: 300, Status: "approved"},
+	}
+	env.repo.payments = []domain.Payment{
+		{ID: "PAY-1", MessID: env.messID, UserID: "a", Amount: 500, Type: domain.PaymentTypeMeal, Status: "approved", Month: testMonth},
+		{ID: "PAY-2", MessID: env.messID, UserID: "b", Amount: 100, Type: domain.PaymentTypeMeal, Status: "approved", Month: testMonth},
+	}
+	return env
+}
+
+func TestGetSettlementPlan(t *testing.T) {
+	env := newSettlementEnv()
+
+	plan, err := env.finance.GetSettlementPlan(context.Background(), env.messID, testMonth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.FundBalance != -300 {
+		t.Errorf("got fund balance %v, want -300", plan.FundBalance)
+	}
+	checkTransfers(t, plan.Transfers, []domain.SettlementTransfer{
+		{FromID: "b", ToID: domain.MessFundID, Amount: 300},
+		{FromID: "b", ToID: "a", Amount: 150},
+	})
+}
+
+func TestCompleteSettlementTransfer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("records the payment in the next month and drops the transfer from the plan", func(t *testing.T) {
+		env := newSettlementEnv()
+		if _, err := env.finance.CloseMonth(ctx, env.messID, testMonth, "a"); err != nil {
+			t.Fatal(err)
+		}
+		transfer, err := env.finance.CompleteSettlementTransfer(ctx, env.messID, testMonth, "b", domain.MessFundID, 0, "a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if transfer.Amount != 300 || transfer.Status != "done" || len(transfer.PaymentIDs) != 1 {
+			t.Errorf("got transfer %+v, want 300 done with one payment", transfer)
+		}
+		if p := env.repo.payments[len(env.repo.payments)-1]; p.Month != "2024-07" || p.SettlesMonth != testMonth {
+			t.Errorf("got the payment booked in %s settling %q, want 2024-07 settling %s", p.Month, p.SettlesMonth, testMonth)
+		}
+
+		plan, err := env.finance.GetSettlementPlan(ctx, env.messID, testMonth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !plan.Closed {
+			t.Error("got an open plan for a closed month")
+		}
+		checkTransfers(t, plan.Transfers, []domain.SettlementTransfer{{FromID: "b", ToID: "a", Amount: 150}})
+		if len(plan.Completed) != 1 {
+			t.Errorf("got %d completed transfers, want 1", len(plan.Completed))
+		}
+	})
+
+	tests := []struct {
+		name     string
+		open     bool
+		from, to string
+		amount   domain.Money
+		userID   string
+		want     error
+	}{
+		{name: "open month", open: true, from: "b", to: "a", userID: "a", want: domain.ErrConflict},
+		{name: "members cannot settle", from: "b", to: "a", userID: "b", want: domain.ErrForbidden},
+		{name: "transfer not in the plan", from: "a", to: "b", userID: "a", want: domain.ErrInvalid},
+		{name: "amount above the suggestion", from: "b", to: "a", amount: 200, userID: "a", want: domain.ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newSettlementEnv()
+			if !tt.open {
+				if _, err := env.finance.CloseMonth(ctx, env.messID, testMonth, "a"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := env.finance.CompleteSettlementTransfer(ctx, env.messID, testMonth, tt.from, tt.to, tt.amount, tt.userID); !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+			if len(env.repo.payments) != 2 || len(env.repo.transfers) != 0 {
+				t.Errorf("a refused transfer recorded %d payments and %d transfers", len(env.repo.payments)-2, len(env.repo.transfers))
+			}
+		})
+	}
+}
+
+func checkTransfers(t *testing.T, got, want []domain.SettlementTransfer) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d transfers %+v, want %d", len(got), got, len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.FromID != w.FromID || g.ToID != w.ToID || g.Amount != w.Amount {
+			t.Errorf("transfer %d: got %s -> %s %v, want %s -> %s %v", i, g.FromID, g.ToID, g.Amount, w.FromID, w.ToID, w.Amount)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"amar-dera/internal/core/domain"
+	"context"
+	"log"
+	"time"
+)
+
+// --- Trash ---
+
+// GetDeletedServiceCosts lists the mess's service costs waiting in the trash.
+func (s *FinanceService) GetDeletedServiceCosts(ctx context.Context, messID, userID string) ([]domain.ServiceCost, error) {
+	if err := s.authorize(ctx, messID, userID, accessManager, "view the trash"); err != nil {
+		return nil, err
+	}
+	costs, err := s.repo.GetDeletedServiceCosts(ctx, messID)
+	if err != nil {
+		return nil, err
+	}
+	if costs == nil {
+		costs = []domain.ServiceCost{}
+	}
+	return costs, nil
+}
+
+// RestoreServiceCost takes a cost out of the trash and posts it to the
+// ledger again. Its month must still be open.
+func (s *FinanceService) RestoreServiceCost(ctx context.Context, messID, costID, userID string) (*domain.ServiceCost, error) {
+	cost, err := s.repo.GetServiceCostByID(ctx, costID)
+	if err != nil || cost == nil || cost.MessID != messID || !cost.IsDeleted() {
+		return nil, notFound("deleted service cost")
+	}
+	if err := s.authorize(ctx, messID, userID, accessManager, "restore service costs"); err != nil {
+		return nil, err
+	}
+	if err := s.checkMonthLock(ctx, messID, cost.Month); err != nil {
+		return nil, err
+	}
+
+	before := *cost
+	cost.DeletedAt = nil
+	cost.DeletedBy = ""
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.RestoreServiceCost(ctx, costID); err != nil {
+			return err
+		}
+		if err := s.ledger.PostServiceCost(ctx, cost, userID); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, messID, cost.Month, userID, domain.AuditRestore, domain.AuditEntityServiceCost, cost.ID, before, cost)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cost, nil
+}
+
+// GetDeletedBazars lists the mess's bazar entries waiting in the trash.
+func (s *FinanceService) GetDeletedBazars(ctx context.Context, messID, userID string) ([]domain.Bazar, error) {
+	if err := s.authorize(ctx, messID, userID, accessManager, "view the trash"); err != nil {
+		return nil, err
+	}
+	bazars, err := s.repo.GetDeletedBazars(ctx, messID)
+	if err != nil {
+		return nil, err
+	}
+	if bazars == nil {
+		bazars = []domain.Bazar{}
+	}
+	return bazars, nil
+}
+
+// RestoreBazar takes a bazar entry out of the trash. Approved entries are
+// posted to the ledger again. Its month must still be open.
+func (s *FinanceService) RestoreBazar(ctx context.Context, messID, bazarID, userID string) (*domain.Bazar, error) {
+	bazar, err := s.repo.GetBazarByID(ctx, bazarID)
+	if err != nil || bazar == nil || bazar.MessID != messID || !bazar.IsDeleted() {
+		return nil, notFound("deleted bazar entry")
+	}
+	if err := s.authorize(ctx, messID, userID, accessManager, "restore bazar entries"); err != nil {
+		return nil, err
+	}
+	if err := s.checkMonthLock(ctx, messID, bazar.Month); err != nil {
+		return nil, err
+	}
+
+	before := *bazar
+	bazar.DeletedAt = nil
+	bazar.DeletedBy = ""
+	err = s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.RestoreBazar(ctx, bazarID); err != nil {
+			return err
+		}
+		if bazar.Status == "approved" {
+			if err := s.ledger.PostBazar(ctx, bazar, userID); err != nil {
+				return err
+			}
+		}
+		return s.audit.Record(ctx, messID, bazar.Month, userID, domain.AuditRestore, domain.AuditEntityBazar, bazar.ID, before, bazar)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bazar, nil
+}
+
+// PurgeTrash permanently removes bazar entries and service costs deleted
+// before the cutoff, returning what was removed. Their ledger postings were
+// already reversed when they were deleted.
+func (s *FinanceService) PurgeTrash(ctx context.Context, before time.Time) ([]domain.Bazar, []domain.ServiceCost, error) {
+	bazars, err := s.repo.GetBazarsDeletedBefore(ctx, before)
+	if err != nil {
+		return nil, nil, err
+	}
+	costs, err := s.repo.GetServiceCostsDeletedBefore(ctx, before)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var purgedBazars []domain.Bazar
+	for _, b := range bazars {
+		err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+			if err := s.repo.PurgeBazar(ctx, b.ID); err != nil {
+				return err
+			}
+			return s.audit.Record(ctx, b.MessID, b.Month, auditSystemActor, domain.AuditPurge, domain.AuditEntityBazar, b.ID, b, nil)
+		})
+		if err != nil {
+			log.Printf("Failed to purge bazar %s: %v", b.ID, err)
+			continue
+		}
+		purgedBazars = append(purgedBazars, b)
+	}
+
+	var purgedCosts []domain.ServiceCost
+	for _, c := range costs {
+		err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+			if err := s.repo.PurgeServiceCost(ctx, c.ID); err != nil {
+				return err
+			}
+			// Keep the template from copying the purged cost back in
+			if c.TemplateID != "" {
+				if err := s.repo.SkipCostTemplateMonth(ctx, c.TemplateID, c.Month); err != nil {
+					return err
+				}
+			}
+			return s.audit.Record(ctx, c.MessID, c.Month, auditSystemActor, domain.AuditPurge, domain.AuditEntityServiceCost, c.ID, c, nil)
+		})
+		if err != nil {
+			log.Printf("Failed to purge service cost %s: %v", c.ID, err)
+			continue
+		}
+		purgedCosts = append(purgedCosts, c)
+	}
+	return purgedBazars, purgedCosts, nil
+}
+
+// StartTrashPurger starts a background goroutine that permanently removes
+// deleted bazar entries and service costs, with their attachments, once they
+// have been in the trash longer than the retention period.
+func StartTrashPurger(financeService *FinanceService, attachmentService *AttachmentService, retention time.Duration) {
+	go func() {
+		for {
+			ctx := context.Background()
+			bazars, costs, err := financeService.PurgeTrash(ctx, time.Now().Add(-retention))
+			if err != nil {
+				log.Printf("Failed to purge trash: %v", err)
+			}
+			for _, b := range bazars {
+				if err := attachmentService.DeleteForEntity(ctx, b.MessID, domain.AttachmentEntityBazar, b.ID); err != nil {
+					log.Printf("Failed to remove attachments of bazar %s: %v", b.ID, err)
+				}
+			}
+			for _, c := range costs {
+				if err := attachmentService.DeleteForEntity(ctx, c.MessID, domain.AttachmentEntityServiceCost, c.ID); err != nil {
+					log.Printf("Failed to remove attachments of service cost %s: %v", c.ID, err)
+				}
+			}
+			if n := len(bazars) + len(costs); n > 0 {
+				log.Printf("Purged %d deleted finance records", n)
+			}
+
+			time.Sleep(24 * time.Hour)
+		}
+	}()
+}
@@ -11,12 +11,13 @@ import (
 )
 
 type UserService struct {
-	repo domain.UserRepository
-	cfg  *config.Config
+	repo     domain.UserRepository
+	messRepo domain.MessRepository
+	cfg      *config.Config
 }
 
-func NewUserService(repo domain.UserRepository, cfg *config.Config) *UserService {
-	return &UserService{repo: repo, cfg: cfg}
+func NewUserService(repo domain.UserRepository, messRepo domain.MessRepository, cfg *config.Config) *UserService {
+	return &UserService{repo: repo, messRepo: messRepo, cfg: cfg}
 }
 
 func (s *UserService) LoginWithGoogle(ctx context.Context, idToken string) (*domain.User, string, error) {
@@ -80,6 +81,35 @@ func (s *UserService) LoginWithGoogle(ctx context.Context, idToken string) (*dom
 	return user, token, nil
 }
 
-func (s *UserService) GetUserProfile(ctx context.Context, id string) (*domain.User, error) {
-	return s.repo.GetByID(ctx, id)
+// GetUserProfile returns the user with every mess they are an active or
+// suspended member of, and their roles there.
+func (s *UserService) GetUserProfile(ctx context.Context, id string) (*domain.UserProfile, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	profile := &domain.UserProfile{User: user, Memberships: []domain.Membership{}}
+	for _, messID := range user.Messes {
+		mess, err := s.messRepo.GetByID(ctx, messID)
+		if err != nil || mess == nil {
+			continue
+		}
+		member := mess.FindMember(id)
+		if member == nil || (member.Status != "active" && member.Status != "suspended") {
+			continue
+		}
+		profile.Memberships = append(profile.Memberships, domain.Membership{
+			MessID:   mess.ID,
+			MessName: mess.Name,
+			Roles:    member.Roles,
+			Status:   member.Status,
+			IsOwner:  mess.AdminID == id,
+			Current:  user.CurrentMessID == mess.ID,
+		})
+	}
+	return profile, nil
 }
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"amar-dera/internal/core/domain"
+	"amar-dera/internal/core/services"
+	"amar-dera/pkg/utils"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AttachmentHandler struct {
+	service *services.AttachmentService
+}
+
+func NewAttachmentHandler(service *services.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{service: service}
+}
+
+func (h *AttachmentHandler) UploadBazarAttachment(c *gin.Context) {
+	h.upload(c, domain.AttachmentEntityBazar, c.Param("bazarId"))
+}
+
+func (h *AttachmentHandler) UploadServiceCostAttachment(c *gin.Context) {
+	h.upload(c, domain.AttachmentEntityServiceCost, c.Param("costId"))
+}
+
+func (h *AttachmentHandler) ListBazarAttachments(c *gin.Context) {
+	h.list(c, domain.AttachmentEntityBazar, c.Param("bazarId"))
+}
+
+func (h *AttachmentHandler) ListServiceCostAttachments(c *gin.Context) {
+	h.list(c, domain.AttachmentEntityServiceCost, c.Param("costId"))
+}
+
+// upload reads the multipart "file" field, capped at the configured size.
+func (h *AttachmentHandler) upload(c *gin.Context, entityType domain.AttachmentEntity, entityID string) {
+	maxSize := h.service.MaxSize()
+	// Leave room for the multipart envelope around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+1<<20)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "file required", err)
+		return
+	}
+	if header.Size > maxSize {
+		utils.SendError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("file is larger than %d MB", maxSize>>20), nil)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "failed to read file", err)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "failed to read file", err)
+		return
+	}
+
+	userID := c.GetString("userID")
+	attachment, err := h.service.Upload(c.Request.Context(), c.Param("id"), entityType, entityID, header.Filename, data, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to upload attachment", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusCreated, "attachment uploaded", attachment)
+}
+
+func (h *AttachmentHandler) list(c *gin.Context, entityType domain.AttachmentEntity, entityID string) {
+	userID := c.GetString("userID")
+	attachments, err := h.service.List(c.Request.Context(), c.Param("id"), entityType, entityID, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to fetch attachments", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "attachments", attachments)
+}
+
+// GetAttachment streams the file, or its thumbnail with ?thumb=true.
+func (h *AttachmentHandler) GetAttachment(c *gin.Context) {
+	messID := c.Param("id")
+	attachmentID := c.Param("attachmentId")
+	thumb := c.Query("thumb") == "true"
+
+	userID := c.GetString("userID")
+	attachment, file, err := h.service.Open(c.Request.Context(), messID, attachmentID, thumb, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusNotFound), "failed to fetch attachment", err)
+		return
+	}
+	defer file.Close()
+
+	contentType := attachment.ContentType
+	size := attachment.Size
+	if thumb {
+		contentType = "image/jpeg"
+		size = -1
+	}
+	headers := map[string]string{
+		"Content-Disposition": fmt.Sprintf("inline; filename=%q", attachment.FileName),
+		"Cache-Control":       "private, max-age=86400",
+	}
+	c.DataFromReader(http.StatusOK, size, contentType, file, headers)
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"amar-dera/internal/core/domain"
+	"amar-dera/internal/core/services"
+	"amar-dera/pkg/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	service *services.AuditService
+}
+
+func NewAuditHandler(service *services.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+func (h *AuditHandler) GetAuditLog(c *gin.Context) {
+	filter := domain.AuditFilter{
+		MessID:     c.Param("id"),
+		Month:      c.Query("month"),
+		EntityType: c.Query("entity_type"),
+		ActorID:    c.Query("actor"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil {
+			utils.SendError(c, http.StatusBadRequest, "invalid limit", err)
+			return
+		}
+		filter.Limit = n
+	}
+
+	userID := c.GetString("userID")
+	entries, err := h.service.GetAuditLog(c.Request.Context(), filter, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to fetch audit log", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "audit log", entries)
+}
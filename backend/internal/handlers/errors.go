@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"amar-dera/internal/core/domain"
+	"errors"
+	"net/http"
+)
+
+// statusFor maps well-known service errors to their HTTP status codes and
+// falls back to the given status for everything else.
+func statusFor(err error, fallback int) int {
+	switch {
+	case errors.Is(err, domain.ErrMonthLocked):
+		return http.StatusLocked
+	case errors.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrConflict):
+		return http.StatusConflict
+	default:
+		return fallback
+	}
+}
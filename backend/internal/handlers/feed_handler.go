@@ -33,11 +33,9 @@ func (h *FeedHandler) CreatePost(c *gin.Context) {
 	}
 
 	userID := c.GetString("userID")
-	messID := c.GetString("messID") // Assuming messID is in context from middleware if user is in a mess
 
 	post := &domain.FeedPost{
 		UserID:      userID,
-		MessID:      messID,
 		Category:    req.Category,
 		Title:       req.Title,
 		Description: req.Description,
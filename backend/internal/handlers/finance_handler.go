@@ -25,9 +25,11 @@ func (h *FinanceHandler) AddServiceCost(c *gin.Context) {
 		return
 	}
 
+	req.MessID = c.Param("id")
+
 	userID := c.GetString("userID")
 	if err := h.service.AddServiceCost(c.Request.Context(), req, userID); err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to add cost", err)
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to add cost", err)
 		return
 	}
 	utils.SendSuccess(c, http.StatusCreated, "cost added", nil)
@@ -43,21 +45,46 @@ func (h *FinanceHandler) GetServiceCosts(c *gin.Context) {
 
 	costs, err := h.service.GetServiceCosts(c.Request.Context(), messID, month)
 	if err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to fetch costs", err)
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to fetch costs", err)
 		return
 	}
 	utils.SendSuccess(c, http.StatusOK, "service costs", costs)
 }
 
 func (h *FinanceHandler) DeleteServiceCost(c *gin.Context) {
+	messID := c.Param("id")
 	costID := c.Param("costId")
-	if err := h.service.DeleteServiceCost(c.Request.Context(), costID); err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to delete cost", err)
+	userID := c.GetString("userID")
+	if err := h.service.DeleteServiceCost(c.Request.Context(), messID, costID, userID); err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to delete cost", err)
 		return
 	}
 	utils.SendSuccess(c, http.StatusOK, "cost deleted", nil)
 }
 
+func (h *FinanceHandler) GetDeletedServiceCosts(c *gin.Context) {
+	messID := c.Param("id")
+	userID := c.GetString("userID")
+	costs, err := h.service.GetDeletedServiceCosts(c.Request.Context(), messID, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusForbidden), "failed to fetch deleted costs", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "deleted costs", costs)
+}
+
+func (h *FinanceHandler) RestoreServiceCost(c *gin.Context) {
+	messID := c.Param("id")
+	costID := c.Param("costId")
+	userID := c.GetString("userID")
+	cost, err := h.service.RestoreServiceCost(c.Request.Context(), messID, costID, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "failed to restore cost", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "cost restored", cost)
+}
+
 func (h *FinanceHandler) GetMonthSummary(c *gin.Context) {
 	messID := c.Param("id")
 	month := c.Query("month") // YYYY-MM
@@ -69,13 +96,132 @@ func (h *FinanceHandler) GetMonthSummary(c *gin.Context) {
 
 	summary, err := h.service.GenerateMonthlySummary(c.Request.Context(), messID, month)
 	if err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to generate summary", err)
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to generate summary", err)
 		return
 	}
 
 	utils.SendSuccess(c, http.StatusOK, "monthly summary", summary)
 }
 
+func (h *FinanceHandler) GetFinalSummary(c *gin.Context) {
+	messID := c.Param("id")
+	month := c.Query("month")
+	if month == "" {
+		utils.SendError(c, http.StatusBadRequest, "month required", nil)
+		return
+	}
+
+	summary, err := h.service.GetFinalSummary(c.Request.Context(), messID, month)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to generate summary", err)
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, "monthly summary", summary)
+}
+
+func (h *FinanceHandler) CloseMonth(c *gin.Context) {
+	messID := c.Param("id")
+	var req struct {
+		Month string `json:"month" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	userID := c.GetString("userID")
+	snapshot, err := h.service.CloseMonth(c.Request.Context(), messID, req.Month, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to close month", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusCreated, "month closed", snapshot)
+}
+
+func (h *FinanceHandler) GetSummarySnapshots(c *gin.Context) {
+	messID := c.Param("id")
+	month := c.Query("month")
+	if month == "" {
+		utils.SendError(c, http.StatusBadRequest, "month required", nil)
+		return
+	}
+
+	snapshots, err := h.service.GetSummarySnapshots(c.Request.Context(), messID, month)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to fetch snapshots", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "summary snapshots", snapshots)
+}
+
+func (h *FinanceHandler) GetBalanceHistory(c *gin.Context) {
+	messID := c.Param("id")
+	userID := c.GetString("userID")
+	targetUserID := c.Query("user_id")
+	if targetUserID == "" {
+		targetUserID = userID
+	}
+
+	history, err := h.service.GetMemberBalanceHistory(c.Request.Context(), messID, targetUserID, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusForbidden), "failed to fetch balance history", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "balance history", history)
+}
+
+func (h *FinanceHandler) GetSettlementPlan(c *gin.Context) {
+	messID := c.Param("id")
+	month := c.Query("month")
+	if month == "" {
+		utils.SendError(c, http.StatusBadRequest, "month required", nil)
+		return
+	}
+
+	plan, err := h.service.GetSettlementPlan(c.Request.Context(), messID, month)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to build settlement plan", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "settlement plan", plan)
+}
+
+func (h *FinanceHandler) GetFinalSettlement(c *gin.Context) {
+	messID := c.Param("id")
+	memberID := c.Param("userId")
+
+	userID := c.GetString("userID")
+	settlement, err := h.service.GetFinalSettlement(c.Request.Context(), messID, memberID, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to build final settlement", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "final settlement", settlement)
+}
+
+func (h *FinanceHandler) CompleteSettlementTransfer(c *gin.Context) {
+	messID := c.Param("id")
+	var req struct {
+		Month  string       `json:"month" binding:"required"`
+		FromID string       `json:"from_id" binding:"required"`
+		ToID   string       `json:"to_id" binding:"required"`
+		Amount domain.Money `json:"amount"` // Optional: defaults to the suggested amount
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	userID := c.GetString("userID")
+	transfer, err := h.service.CompleteSettlementTransfer(c.Request.Context(), messID, req.Month, req.FromID, req.ToID, req.Amount, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to complete transfer", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusCreated, "transfer completed", transfer)
+}
+
 func (h *FinanceHandler) GetDailyMeals(c *gin.Context) {
 	messID := c.Param("id")
 	month := c.Query("month")
@@ -86,7 +232,7 @@ func (h *FinanceHandler) GetDailyMeals(c *gin.Context) {
 
 	meals, err := h.service.GetDailyMeals(c.Request.Context(), messID, month)
 	if err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to fetch meals", err)
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to fetch meals", err)
 		return
 	}
 	utils.SendSuccess(c, http.StatusOK, "daily meals", meals)
@@ -99,12 +245,18 @@ func (h *FinanceHandler) BatchUpdateMeals(c *gin.Context) {
 		return
 	}
 
+	messID := c.Param("id")
 	userID := c.GetString("userID")
-	if err := h.service.BatchUpdateMeals(c.Request.Context(), req, userID); err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to update meals", err)
+	result, err := h.service.BatchUpdateMeals(c.Request.Context(), messID, req, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusForbidden), "failed to update meals", err)
 		return
 	}
-	utils.SendSuccess(c, http.StatusOK, "meals updated", nil)
+	if result.Rejected > 0 {
+		utils.SendSuccess(c, http.StatusOK, "some meals were rejected", result)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "meals updated", result)
 }
 
 func (h *FinanceHandler) CreateBazar(c *gin.Context) {
@@ -114,10 +266,12 @@ func (h *FinanceHandler) CreateBazar(c *gin.Context) {
 		return
 	}
 
+	req.MessID = c.Param("id")
+
 	userID := c.GetString("userID")
 	req.BuyerID = userID // Always set BuyerID to recorder as per user request (it's not about credit)
 	if err := h.service.CreateBazar(c.Request.Context(), req, userID); err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to create bazar entry", err)
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to create bazar entry", err)
 		return
 	}
 	utils.SendSuccess(c, http.StatusCreated, "bazar entry created", nil)
@@ -133,7 +287,7 @@ func (h *FinanceHandler) GetPendingBazars(c *gin.Context) {
 
 	bazars, err := h.service.GetPendingBazars(c.Request.Context(), messID, month)
 	if err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to fetch bazars", err)
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to fetch bazars", err)
 		return
 	}
 	utils.SendSuccess(c, http.StatusOK, "pending bazars", bazars)
@@ -149,17 +303,37 @@ func (h *FinanceHandler) GetBazars(c *gin.Context) {
 
 	bazars, err := h.service.GetBazars(c.Request.Context(), messID, month)
 	if err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to fetch bazars", err)
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to fetch bazars", err)
 		return
 	}
 	utils.SendSuccess(c, http.StatusOK, "bazar entries", bazars)
 }
 
+func (h *FinanceHandler) GetBazarSpendReport(c *gin.Context) {
+	messID := c.Param("id")
+	from := c.Query("from")
+	to := c.Query("to")
+	if from == "" || to == "" {
+		utils.SendError(c, http.StatusBadRequest, "from and to months required", nil)
+		return
+	}
+	groupBy := c.DefaultQuery("group_by", "category")
+
+	userID := c.GetString("userID")
+	report, err := h.service.GetBazarSpendReport(c.Request.Context(), messID, from, to, groupBy, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "failed to build bazar report", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "bazar spend report", report)
+}
+
 func (h *FinanceHandler) ApproveBazar(c *gin.Context) {
+	messID := c.Param("id")
 	bazarID := c.Param("bazarId")
 	userID := c.GetString("userID")
-	if err := h.service.ApproveBazar(c.Request.Context(), bazarID, userID); err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to approve bazar", err)
+	if err := h.service.ApproveBazar(c.Request.Context(), messID, bazarID, userID); err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to approve bazar", err)
 		return
 	}
 	utils.SendSuccess(c, http.StatusOK, "bazar approved", nil)
@@ -173,26 +347,51 @@ func (h *FinanceHandler) UpdateBazar(c *gin.Context) {
 		return
 	}
 	req.ID = bazarID
+	req.MessID = c.Param("id")
 
 	userID := c.GetString("userID")
 	if err := h.service.UpdateBazar(c.Request.Context(), req, userID); err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to update bazar", err)
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to update bazar", err)
 		return
 	}
 	utils.SendSuccess(c, http.StatusOK, "bazar updated", nil)
 }
 
 func (h *FinanceHandler) DeleteBazar(c *gin.Context) {
+	messID := c.Param("id")
 	bazarID := c.Param("bazarId")
 	userID := c.GetString("userID")
 
-	if err := h.service.DeleteBazar(c.Request.Context(), bazarID, userID); err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to delete bazar", err)
+	if err := h.service.DeleteBazar(c.Request.Context(), messID, bazarID, userID); err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to delete bazar", err)
 		return
 	}
 	utils.SendSuccess(c, http.StatusOK, "bazar deleted", nil)
 }
 
+func (h *FinanceHandler) GetDeletedBazars(c *gin.Context) {
+	messID := c.Param("id")
+	userID := c.GetString("userID")
+	bazars, err := h.service.GetDeletedBazars(c.Request.Context(), messID, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusForbidden), "failed to fetch deleted bazars", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "deleted bazars", bazars)
+}
+
+func (h *FinanceHandler) RestoreBazar(c *gin.Context) {
+	messID := c.Param("id")
+	bazarID := c.Param("bazarId")
+	userID := c.GetString("userID")
+	bazar, err := h.service.RestoreBazar(c.Request.Context(), messID, bazarID, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "failed to restore bazar", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "bazar restored", bazar)
+}
+
 func (h *FinanceHandler) GetMessPayments(c *gin.Context) {
 	messID := c.Param("id")
 	month := c.Query("month")
@@ -203,7 +402,7 @@ func (h *FinanceHandler) GetMessPayments(c *gin.Context) {
 
 	payments, err := h.service.GetMessPayments(c.Request.Context(), messID, month)
 	if err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to fetch payments", err)
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to fetch payments", err)
 		return
 	}
 	utils.SendSuccess(c, http.StatusOK, "mess payments", payments)
@@ -217,10 +416,12 @@ func (h *FinanceHandler) SubmitPayment(c *gin.Context) {
 		return
 	}
 
+	req.MessID = c.Param("id")
+
 	userID := c.GetString("userID")
 	// req.UserID is already bound from JSON (the Payer). userID is the Submitter.
 	if err := h.service.SubmitPayment(c.Request.Context(), req, userID); err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to submit payment", err)
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to submit payment", err)
 		return
 	}
 	utils.SendSuccess(c, http.StatusCreated, "payment submitted", nil)
@@ -236,7 +437,7 @@ func (h *FinanceHandler) GetPendingPayments(c *gin.Context) {
 
 	payments, err := h.service.GetPendingPayments(c.Request.Context(), messID, month)
 	if err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to fetch payments", err)
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to fetch payments", err)
 		return
 	}
 	utils.SendSuccess(c, http.StatusOK, "pending payments", payments)
@@ -248,22 +449,82 @@ func (h *FinanceHandler) GetMemberPayments(c *gin.Context) {
 
 	payments, err := h.service.GetMemberPayments(c.Request.Context(), messID, userID)
 	if err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to fetch member payments", err)
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to fetch member payments", err)
 		return
 	}
 	utils.SendSuccess(c, http.StatusOK, "member payments", payments)
 }
 
 func (h *FinanceHandler) VerifyPayment(c *gin.Context) {
+	messID := c.Param("id")
 	paymentID := c.Param("payId")
 	userID := c.GetString("userID")
-	if err := h.service.VerifyPayment(c.Request.Context(), paymentID, userID); err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to verify payment", err)
+	if err := h.service.VerifyPayment(c.Request.Context(), messID, paymentID, userID); err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to verify payment", err)
 		return
 	}
 	utils.SendSuccess(c, http.StatusOK, "payment verified", nil)
 }
 
+func (h *FinanceHandler) RejectPayment(c *gin.Context) {
+	paymentID := c.Param("payId")
+	var req struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	userID := c.GetString("userID")
+	if err := h.service.RejectPayment(c.Request.Context(), c.Param("id"), paymentID, userID, req.Reason); err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to reject payment", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "payment rejected", nil)
+}
+
+func (h *FinanceHandler) ReversePayment(c *gin.Context) {
+	paymentID := c.Param("payId")
+	var req struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	userID := c.GetString("userID")
+	reversal, err := h.service.ReversePayment(c.Request.Context(), c.Param("id"), paymentID, userID, req.Reason)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to reverse payment", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusCreated, "payment reversed", reversal)
+}
+
+func (h *FinanceHandler) RefundMember(c *gin.Context) {
+	messID := c.Param("id")
+	var req struct {
+		UserID string       `json:"user_id" binding:"required"`
+		Month  string       `json:"month" binding:"required"`
+		Amount domain.Money `json:"amount"` // Optional: defaults to the full positive balance
+		Reason string       `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	userID := c.GetString("userID")
+	refund, err := h.service.RefundMember(c.Request.Context(), messID, req.UserID, req.Month, req.Amount, req.Reason, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to refund member", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusCreated, "refund recorded", refund)
+}
+
 // --- History ---
 
 func (h *FinanceHandler) RequestUnlock(c *gin.Context) {
@@ -274,8 +535,9 @@ func (h *FinanceHandler) RequestUnlock(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.RequestUnlock(c.Request.Context(), messID, month); err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to request unlock", err)
+	userID := c.GetString("userID")
+	if err := h.service.RequestUnlock(c.Request.Context(), messID, month, userID); err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to request unlock", err)
 		return
 	}
 	utils.SendSuccess(c, http.StatusOK, "unlock requested", nil)
@@ -291,7 +553,7 @@ func (h *FinanceHandler) GetLockStatus(c *gin.Context) {
 
 	lock, err := h.service.GetLockStatus(c.Request.Context(), messID, month)
 	if err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to fetch lock status", err)
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to fetch lock status", err)
 		return
 	}
 	if lock == nil {
@@ -313,13 +575,217 @@ func (h *FinanceHandler) SetLockStatus(c *gin.Context) {
 		return
 	}
 
-	// Verify Admin (Ideally middleware or service check, but here checking role via Context if needed, or rely on route protection)
-	// For MVP assuming route is protected for Admin.
-
+	userID := c.GetString("userID")
 	duration := time.Duration(req.Duration) * time.Hour
-	if err := h.service.SetLockStatus(c.Request.Context(), messID, req.Month, req.IsLocked, duration); err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "failed to set lock status", err)
+	if err := h.service.SetLockStatus(c.Request.Context(), messID, req.Month, req.IsLocked, duration, userID); err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to set lock status", err)
 		return
 	}
 	utils.SendSuccess(c, http.StatusOK, "lock status updated", nil)
 }
+
+func (h *FinanceHandler) UpdateServiceCost(c *gin.Context) {
+	var req domain.ServiceCost
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	req.ID = c.Param("costId")
+	req.MessID = c.Param("id")
+
+	userID := c.GetString("userID")
+	if err := h.service.UpdateServiceCost(c.Request.Context(), req, userID); err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to update cost", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "cost updated", nil)
+}
+
+// --- Cost Templates ---
+
+func (h *FinanceHandler) CreateCostTemplate(c *gin.Context) {
+	var req domain.CostTemplate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	req.MessID = c.Param("id")
+
+	userID := c.GetString("userID")
+	template, err := h.service.CreateCostTemplate(c.Request.Context(), req, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "failed to create cost template", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusCreated, "cost template created", template)
+}
+
+func (h *FinanceHandler) GetCostTemplates(c *gin.Context) {
+	messID := c.Param("id")
+	userID := c.GetString("userID")
+	templates, err := h.service.GetCostTemplates(c.Request.Context(), messID, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusForbidden), "failed to fetch cost templates", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "cost templates", templates)
+}
+
+func (h *FinanceHandler) UpdateCostTemplate(c *gin.Context) {
+	var req domain.CostTemplate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	req.ID = c.Param("templateId")
+	req.MessID = c.Param("id")
+
+	userID := c.GetString("userID")
+	template, err := h.service.UpdateCostTemplate(c.Request.Context(), req, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "failed to update cost template", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "cost template updated", template)
+}
+
+func (h *FinanceHandler) DeleteCostTemplate(c *gin.Context) {
+	messID := c.Param("id")
+	templateID := c.Param("templateId")
+	userID := c.GetString("userID")
+	if err := h.service.DeleteCostTemplate(c.Request.Context(), messID, templateID, userID); err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "failed to delete cost template", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "cost template deleted", nil)
+}
+
+func (h *FinanceHandler) ApplyCostTemplates(c *gin.Context) {
+	messID := c.Param("id")
+	month := c.Query("month")
+	if month == "" {
+		utils.SendError(c, http.StatusBadRequest, "month required", nil)
+		return
+	}
+
+	userID := c.GetString("userID")
+	costs, err := h.service.ApplyCostTemplates(c.Request.Context(), messID, month, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "failed to apply cost templates", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "cost templates applied", costs)
+}
+
+// --- Meal Schedules ---
+
+func (h *FinanceHandler) GetMealSchedule(c *gin.Context) {
+	messID := c.Param("id")
+	targetUserID := c.Query("user_id") // Managers may look up other members
+	userID := c.GetString("userID")
+	schedule, err := h.service.GetMealSchedule(c.Request.Context(), messID, targetUserID, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusForbidden), "failed to fetch meal schedule", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "meal schedule", schedule)
+}
+
+func (h *FinanceHandler) GetMealSchedules(c *gin.Context) {
+	messID := c.Param("id")
+	userID := c.GetString("userID")
+	schedules, err := h.service.GetMealSchedules(c.Request.Context(), messID, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusForbidden), "failed to fetch meal schedules", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "meal schedules", schedules)
+}
+
+func (h *FinanceHandler) SetMealSchedule(c *gin.Context) {
+	var req domain.MealSchedule
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	req.MessID = c.Param("id")
+
+	userID := c.GetString("userID")
+	schedule, err := h.service.SetMealSchedule(c.Request.Context(), req, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "failed to save meal schedule", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "meal schedule saved", schedule)
+}
+
+func (h *FinanceHandler) CreateMealOff(c *gin.Context) {
+	var req domain.MealOff
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	req.MessID = c.Param("id")
+
+	userID := c.GetString("userID")
+	off, err := h.service.CreateMealOff(c.Request.Context(), req, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "failed to request meal-off", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusCreated, "meal-off requested", off)
+}
+
+func (h *FinanceHandler) GetMealOffs(c *gin.Context) {
+	messID := c.Param("id")
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "from date required (YYYY-MM-DD)", err)
+		return
+	}
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "to date required (YYYY-MM-DD)", err)
+		return
+	}
+
+	userID := c.GetString("userID")
+	offs, err := h.service.GetMealOffs(c.Request.Context(), messID, from, to, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusForbidden), "failed to fetch meal-offs", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "meal-offs", offs)
+}
+
+func (h *FinanceHandler) CancelMealOff(c *gin.Context) {
+	messID := c.Param("id")
+	offID := c.Param("offId")
+	userID := c.GetString("userID")
+	if err := h.service.CancelMealOff(c.Request.Context(), messID, offID, userID); err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "failed to cancel meal-off", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "meal-off cancelled", nil)
+}
+
+func (h *FinanceHandler) GetMealHeadcount(c *gin.Context) {
+	messID := c.Param("id")
+	date := domain.MealDate(time.Now()).AddDate(0, 0, 1) // Tomorrow by default
+	if d := c.Query("date"); d != "" {
+		parsed, err := time.Parse("2006-01-02", d)
+		if err != nil {
+			utils.SendError(c, http.StatusBadRequest, "date must be YYYY-MM-DD", err)
+			return
+		}
+		date = parsed
+	}
+
+	userID := c.GetString("userID")
+	headcount, err := h.service.GetMealHeadcount(c.Request.Context(), messID, date, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusForbidden), "failed to fetch headcount", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "meal headcount", headcount)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"amar-dera/internal/core/services"
+	"amar-dera/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LedgerHandler struct {
+	service *services.LedgerService
+}
+
+func NewLedgerHandler(service *services.LedgerService) *LedgerHandler {
+	return &LedgerHandler{service: service}
+}
+
+func (h *LedgerHandler) GetTrialBalance(c *gin.Context) {
+	messID := c.Param("id")
+	month := c.Query("month")
+	if month == "" {
+		utils.SendError(c, http.StatusBadRequest, "month required", nil)
+		return
+	}
+
+	userID := c.GetString("userID")
+	balances, err := h.service.GetTrialBalance(c.Request.Context(), messID, month, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to fetch balances", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "account balances", balances)
+}
+
+func (h *LedgerHandler) GetStatement(c *gin.Context) {
+	messID := c.Param("id")
+	account := c.Query("account") // Defaults to the caller's member account
+
+	userID := c.GetString("userID")
+	statement, err := h.service.GetStatement(c.Request.Context(), messID, account, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to fetch statement", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "account statement", statement)
+}
+
+func (h *LedgerHandler) CheckIntegrity(c *gin.Context) {
+	messID := c.Param("id")
+	month := c.Query("month")
+	if month == "" {
+		utils.SendError(c, http.StatusBadRequest, "month required", nil)
+		return
+	}
+
+	userID := c.GetString("userID")
+	result, err := h.service.CheckIntegrity(c.Request.Context(), messID, month, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to check ledger", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "ledger integrity", result)
+}
+
+func (h *LedgerHandler) SyncMonth(c *gin.Context) {
+	messID := c.Param("id")
+	var req struct {
+		Month string `json:"month" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	userID := c.GetString("userID")
+	posted, err := h.service.SyncMonth(c.Request.Context(), messID, req.Month, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to sync ledger", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "ledger synced", gin.H{"posted": posted})
+}
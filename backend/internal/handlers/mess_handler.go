@@ -4,18 +4,22 @@ import (
 	"amar-dera/internal/core/domain"
 	"amar-dera/internal/core/services"
 	"amar-dera/pkg/utils"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type MessHandler struct {
-	service *services.MessService
+	service        *services.MessService
+	financeService *services.FinanceService
 }
 
-func NewMessHandler(service *services.MessService) *MessHandler {
-	return &MessHandler{service: service}
+func NewMessHandler(service *services.MessService, financeService *services.FinanceService) *MessHandler {
+	return &MessHandler{service: service, financeService: financeService}
 }
 
 func (h *MessHandler) CreateMess(c *gin.Context) {
@@ -72,13 +76,46 @@ func (h *MessHandler) ApproveMember(c *gin.Context) {
 	userID := c.GetString("userID")
 	err := h.service.ApproveMember(c.Request.Context(), messID, req.UserID, userID)
 	if err != nil {
-		utils.SendError(c, http.StatusForbidden, "approval failed", err)
+		utils.SendError(c, statusFor(err, http.StatusForbidden), "approval failed", err)
 		return
 	}
 
 	utils.SendSuccess(c, http.StatusOK, "member approved", nil)
 }
 
+func (h *MessHandler) RejectMember(c *gin.Context) {
+	messID := c.Param("id")
+	var req struct {
+		UserID string `json:"user_id" binding:"required"`
+		Reason string `json:"reason"`
+	}
+	// The reason is optional, and so is the body
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.SendError(c, http.StatusBadRequest, "Missing user_id in request", err)
+		return
+	}
+
+	userID := c.GetString("userID")
+	if err := h.service.RejectMember(c.Request.Context(), messID, req.UserID, userID, req.Reason); err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "rejection failed", err)
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, "join request rejected", nil)
+}
+
+func (h *MessHandler) CancelJoinRequest(c *gin.Context) {
+	messID := c.Param("id")
+	userID := c.GetString("userID")
+
+	if err := h.service.CancelJoinRequest(c.Request.Context(), messID, userID); err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "failed to cancel join request", err)
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, "join request cancelled", nil)
+}
+
 func (h *MessHandler) GetRequests(c *gin.Context) {
 	messID := c.Param("id")
 	userID := c.GetString("userID")
@@ -165,3 +202,227 @@ func (h *MessHandler) LeaveMess(c *gin.Context) {
 
 	utils.SendSuccess(c, http.StatusOK, "successfully left the mess", nil)
 }
+
+func (h *MessHandler) SwitchMess(c *gin.Context) {
+	messID := c.Param("id")
+	userID := c.GetString("userID")
+
+	if err := h.service.SwitchCurrentMess(c.Request.Context(), messID, userID); err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to switch mess", err)
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, "current mess switched", nil)
+}
+
+func (h *MessHandler) GetMealConfig(c *gin.Context) {
+	messID := c.Param("id")
+	userID := c.GetString("userID")
+	config, err := h.service.GetMealConfig(c.Request.Context(), messID, userID)
+	if err != nil {
+		utils.SendError(c, http.StatusForbidden, "failed to fetch meal configuration", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "meal configuration", config)
+}
+
+func (h *MessHandler) UpdateMealConfig(c *gin.Context) {
+	messID := c.Param("id")
+	var req domain.MealConfig
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	userID := c.GetString("userID")
+	if err := h.service.UpdateMealConfig(c.Request.Context(), messID, userID, req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "failed to update meal configuration", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "meal configuration updated", nil)
+}
+
+func (h *MessHandler) CreateInvite(c *gin.Context) {
+	messID := c.Param("id")
+	var req struct {
+		MaxUses        int      `json:"max_uses"`
+		ExpiresInHours int      `json:"expires_in_hours"`
+		Roles          []string `json:"roles"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	roles := make([]domain.Role, len(req.Roles))
+	for i, r := range req.Roles {
+		roles[i] = domain.Role(r)
+	}
+
+	userID := c.GetString("userID")
+	ttl := time.Duration(req.ExpiresInHours) * time.Hour
+	invite, err := h.service.CreateInvite(c.Request.Context(), messID, userID, req.MaxUses, ttl, roles)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "failed to create invite", err)
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusCreated, "invite created", invite)
+}
+
+func (h *MessHandler) GetInvites(c *gin.Context) {
+	messID := c.Param("id")
+	userID := c.GetString("userID")
+
+	invites, err := h.service.GetInvites(c.Request.Context(), messID, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to fetch invites", err)
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, "invites", invites)
+}
+
+func (h *MessHandler) RevokeInvite(c *gin.Context) {
+	messID := c.Param("id")
+	inviteID := c.Param("inviteId")
+	userID := c.GetString("userID")
+
+	if err := h.service.RevokeInvite(c.Request.Context(), messID, inviteID, userID); err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "failed to revoke invite", err)
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, "invite revoked", nil)
+}
+
+func (h *MessHandler) GetInvitePreview(c *gin.Context) {
+	token := c.Param("token")
+
+	preview, err := h.service.GetInvitePreview(c.Request.Context(), token)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusGone), "invalid invite", err)
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, "invite", preview)
+}
+
+func (h *MessHandler) AcceptInvite(c *gin.Context) {
+	token := c.Param("token")
+	userID := c.GetString("userID")
+
+	mess, err := h.service.AcceptInvite(c.Request.Context(), token, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "failed to join with invite", err)
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, "joined mess", mess)
+}
+
+func (h *MessHandler) RemoveMember(c *gin.Context) {
+	messID := c.Param("id")
+	targetUserID := c.Param("userId")
+	userID := c.GetString("userID")
+
+	if err := h.service.RemoveMember(c.Request.Context(), messID, targetUserID, userID); err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "failed to remove member", err)
+		return
+	}
+
+	settlement, err := h.financeService.GetFinalSettlement(c.Request.Context(), messID, targetUserID, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusInternalServerError), "member removed, but the final settlement failed", err)
+		return
+	}
+	utils.SendSuccess(c, http.StatusOK, "member removed", settlement)
+}
+
+func (h *MessHandler) SuspendMember(c *gin.Context) {
+	messID := c.Param("id")
+	targetUserID := c.Param("userId")
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	userID := c.GetString("userID")
+	if err := h.service.SuspendMember(c.Request.Context(), messID, targetUserID, userID, req.Reason); err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "failed to suspend member", err)
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, "member suspended", nil)
+}
+
+func (h *MessHandler) ReinstateMember(c *gin.Context) {
+	messID := c.Param("id")
+	targetUserID := c.Param("userId")
+	userID := c.GetString("userID")
+
+	if err := h.service.ReinstateMember(c.Request.Context(), messID, targetUserID, userID); err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "failed to reinstate member", err)
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, "member reinstated", nil)
+}
+
+func (h *MessHandler) TransferOwnership(c *gin.Context) {
+	messID := c.Param("id")
+	var req struct {
+		UserID string `json:"user_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Missing user_id in request", err)
+		return
+	}
+
+	userID := c.GetString("userID")
+	if err := h.service.TransferOwnership(c.Request.Context(), messID, req.UserID, userID); err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "failed to transfer ownership", err)
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, "ownership transfer waiting for the new owner to accept", nil)
+}
+
+func (h *MessHandler) AcceptOwnership(c *gin.Context) {
+	messID := c.Param("id")
+	userID := c.GetString("userID")
+
+	if err := h.service.AcceptOwnership(c.Request.Context(), messID, userID); err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "failed to accept ownership", err)
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, "you now own the mess", nil)
+}
+
+func (h *MessHandler) CancelOwnershipTransfer(c *gin.Context) {
+	messID := c.Param("id")
+	userID := c.GetString("userID")
+
+	if err := h.service.CancelOwnershipTransfer(c.Request.Context(), messID, userID); err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "failed to cancel ownership transfer", err)
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, "ownership transfer cancelled", nil)
+}
+
+func (h *MessHandler) DissolveMess(c *gin.Context) {
+	messID := c.Param("id")
+	userID := c.GetString("userID")
+
+	plan, err := h.service.DissolveMess(c.Request.Context(), messID, userID)
+	if err != nil {
+		utils.SendError(c, statusFor(err, http.StatusBadRequest), "failed to dissolve mess", err)
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, "mess dissolved", plan)
+}
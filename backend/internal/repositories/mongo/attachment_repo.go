@@ -0,0 +1,59 @@
+package mongo
+
+import (
+	"amar-dera/internal/core/domain"
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AttachmentRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAttachmentRepository(db *mongo.Database) domain.AttachmentRepository {
+	return &AttachmentRepository{
+		collection: db.Collection("attachments"),
+	}
+}
+
+func (r *AttachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	_, err := r.collection.InsertOne(ctx, attachment)
+	return err
+}
+
+func (r *AttachmentRepository) GetByID(ctx context.Context, id string) (*domain.Attachment, error) {
+	var attachment domain.Attachment
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&attachment)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *AttachmentRepository) ListByEntity(ctx context.Context, messID string, entityType domain.AttachmentEntity, entityID string) ([]domain.Attachment, error) {
+	filter := bson.M{"mess_id": messID, "entity_type": entityType, "entity_id": entityID}
+	opts := options.Find().SetSort(bson.D{{Key: "uploaded_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var attachments []domain.Attachment
+	if err := cursor.All(ctx, &attachments); err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (r *AttachmentRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
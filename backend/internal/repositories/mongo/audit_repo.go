@@ -0,0 +1,55 @@
+package mongo
+
+import (
+	"amar-dera/internal/core/domain"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AuditRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAuditRepository(db *mongo.Database) domain.AuditRepository {
+	return &AuditRepository{
+		collection: db.Collection("audit_log"),
+	}
+}
+
+func (r *AuditRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	_, err := r.collection.InsertOne(ctx, entry)
+	return err
+}
+
+// List returns matching entries, newest first.
+func (r *AuditRepository) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	query := bson.M{"mess_id": filter.MessID}
+	if filter.Month != "" {
+		query["month"] = filter.Month
+	}
+	if filter.EntityType != "" {
+		query["entity_type"] = filter.EntityType
+	}
+	if filter.ActorID != "" {
+		query["actor_id"] = filter.ActorID
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if filter.Limit > 0 {
+		opts.SetLimit(filter.Limit)
+	}
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []domain.AuditEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
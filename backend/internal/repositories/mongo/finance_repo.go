@@ -4,6 +4,7 @@ import (
 	"amar-dera/internal/core/domain"
 	"context"
 	"errors"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -23,11 +24,71 @@ func NewFinanceRepository(db *mongo.Database) domain.FinanceRepository {
 // --- Service Costs ---
 func (r *FinanceRepository) AddServiceCost(ctx context.Context, cost *domain.ServiceCost) error {
 	_, err := r.db.Collection("service_costs").InsertOne(ctx, cost)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrDuplicate
+	}
 	return err
 }
 
+func (r *FinanceRepository) GetServiceCostByID(ctx context.Context, costID string) (*domain.ServiceCost, error) {
+	var cost domain.ServiceCost
+	err := r.db.Collection("service_costs").FindOne(ctx, bson.M{"_id": costID}).Decode(&cost)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cost, nil
+}
+
+// notDeleted matches documents that are not in the trash.
+var notDeleted = bson.M{"$exists": false}
+
 func (r *FinanceRepository) GetServiceCosts(ctx context.Context, messID, month string) ([]domain.ServiceCost, error) {
-	filter := bson.M{"mess_id": messID, "month": month}
+	filter := bson.M{"mess_id": messID, "month": month, "deleted_at": notDeleted}
+	cursor, err := r.db.Collection("service_costs").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var costs []domain.ServiceCost
+	if err = cursor.All(ctx, &costs); err != nil {
+		return nil, err
+	}
+	return costs, nil
+}
+
+// DeleteServiceCost moves a cost to the trash. PurgeServiceCost removes it for good.
+func (r *FinanceRepository) DeleteServiceCost(ctx context.Context, costID, deletedBy string, deletedAt time.Time) error {
+	filter := bson.M{"_id": costID}
+	update := bson.M{"$set": bson.M{"deleted_at": deletedAt, "deleted_by": deletedBy}}
+	_, err := r.db.Collection("service_costs").UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *FinanceRepository) RestoreServiceCost(ctx context.Context, costID string) error {
+	filter := bson.M{"_id": costID}
+	update := bson.M{"$unset": bson.M{"deleted_at": "", "deleted_by": ""}}
+	_, err := r.db.Collection("service_costs").UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *FinanceRepository) GetDeletedServiceCosts(ctx context.Context, messID string) ([]domain.ServiceCost, error) {
+	filter := bson.M{"mess_id": messID, "deleted_at": bson.M{"$exists": true}}
+	opts := options.Find().SetSort(bson.M{"deleted_at": -1})
+	cursor, err := r.db.Collection("service_costs").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var costs []domain.ServiceCost
+	if err = cursor.All(ctx, &costs); err != nil {
+		return nil, err
+	}
+	return costs, nil
+}
+
+func (r *FinanceRepository) GetServiceCostsDeletedBefore(ctx context.Context, before time.Time) ([]domain.ServiceCost, error) {
+	filter := bson.M{"deleted_at": bson.M{"$lt": before}}
 	cursor, err := r.db.Collection("service_costs").Find(ctx, filter)
 	if err != nil {
 		return nil, err
@@ -39,11 +100,120 @@ func (r *FinanceRepository) GetServiceCosts(ctx context.Context, messID, month s
 	return costs, nil
 }
 
-func (r *FinanceRepository) DeleteServiceCost(ctx context.Context, costID string) error {
+func (r *FinanceRepository) PurgeServiceCost(ctx context.Context, costID string) error {
 	_, err := r.db.Collection("service_costs").DeleteOne(ctx, bson.M{"_id": costID})
 	return err
 }
 
+func (r *FinanceRepository) AddServiceCostAttachment(ctx context.Context, costID, attachmentID string) error {
+	filter := bson.M{"_id": costID}
+	update := bson.M{"$addToSet": bson.M{"attachments": attachmentID}}
+	_, err := r.db.Collection("service_costs").UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *FinanceRepository) UpdateServiceCost(ctx context.Context, cost *domain.ServiceCost) error {
+	filter := bson.M{"_id": cost.ID}
+	update := bson.M{"$set": bson.M{
+		"name":   cost.Name,
+		"amount": cost.Amount,
+		"shares": cost.Shares,
+	}}
+	_, err := r.db.Collection("service_costs").UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *FinanceRepository) GetServiceCostByTemplate(ctx context.Context, messID, templateID, month string) (*domain.ServiceCost, error) {
+	var cost domain.ServiceCost
+	filter := bson.M{"mess_id": messID, "template_id": templateID, "month": month}
+	err := r.db.Collection("service_costs").FindOne(ctx, filter).Decode(&cost)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cost, nil
+}
+
+// --- Cost Templates ---
+func (r *FinanceRepository) CreateCostTemplate(ctx context.Context, template *domain.CostTemplate) error {
+	_, err := r.db.Collection("cost_templates").InsertOne(ctx, template)
+	return err
+}
+
+func (r *FinanceRepository) GetCostTemplateByID(ctx context.Context, templateID string) (*domain.CostTemplate, error) {
+	var template domain.CostTemplate
+	err := r.db.Collection("cost_templates").FindOne(ctx, bson.M{"_id": templateID}).Decode(&template)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *FinanceRepository) GetCostTemplates(ctx context.Context, messID string) ([]domain.CostTemplate, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.db.Collection("cost_templates").Find(ctx, bson.M{"mess_id": messID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var templates []domain.CostTemplate
+	if err = cursor.All(ctx, &templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// GetCostTemplatesForMonth returns the templates of every mess that cover the month.
+func (r *FinanceRepository) GetCostTemplatesForMonth(ctx context.Context, month string) ([]domain.CostTemplate, error) {
+	filter := bson.M{
+		"start_month": bson.M{"$lte": month},
+		"$or": bson.A{
+			bson.M{"end_month": bson.M{"$exists": false}},
+			bson.M{"end_month": ""},
+			bson.M{"end_month": bson.M{"$gte": month}},
+		},
+	}
+	cursor, err := r.db.Collection("cost_templates").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var templates []domain.CostTemplate
+	if err = cursor.All(ctx, &templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (r *FinanceRepository) UpdateCostTemplate(ctx context.Context, template *domain.CostTemplate) error {
+	filter := bson.M{"_id": template.ID}
+	update := bson.M{"$set": bson.M{
+		"name":        template.Name,
+		"amount":      template.Amount,
+		"shares":      template.Shares,
+		"start_month": template.StartMonth,
+		"end_month":   template.EndMonth,
+		"updated_at":  template.UpdatedAt,
+	}}
+	_, err := r.db.Collection("cost_templates").UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *FinanceRepository) DeleteCostTemplate(ctx context.Context, templateID string) error {
+	_, err := r.db.Collection("cost_templates").DeleteOne(ctx, bson.M{"_id": templateID})
+	return err
+}
+
+func (r *FinanceRepository) SkipCostTemplateMonth(ctx context.Context, templateID, month string) error {
+	filter := bson.M{"_id": templateID}
+	update := bson.M{"$addToSet": bson.M{"skipped_months": month}}
+	_, err := r.db.Collection("cost_templates").UpdateOne(ctx, filter, update)
+	return err
+}
+
 // --- Payments ---
 func (r *FinanceRepository) CreatePayment(ctx context.Context, payment *domain.Payment) error {
 	_, err := r.db.Collection("payments").InsertOne(ctx, payment)
@@ -94,6 +264,39 @@ func (r *FinanceRepository) UpdatePaymentStatus(ctx context.Context, paymentID,
 	return err
 }
 
+func (r *FinanceRepository) RejectPayment(ctx context.Context, paymentID, rejecterID, reason string) error {
+	filter := bson.M{"_id": paymentID, "status": "pending"}
+	update := bson.M{"$set": bson.M{"status": "rejected", "rejected_by": rejecterID, "reason": reason}}
+	_, err := r.db.Collection("payments").UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *FinanceRepository) MarkPaymentReversed(ctx context.Context, paymentID, reversalID string) error {
+	filter := bson.M{"_id": paymentID}
+	update := bson.M{"$set": bson.M{"reversed_by": reversalID}}
+	_, err := r.db.Collection("payments").UpdateOne(ctx, filter, update)
+	return err
+}
+
+// GetSettlingPayments returns payments booked after the month that pay off
+// the balance of the month or of an earlier one.
+func (r *FinanceRepository) GetSettlingPayments(ctx context.Context, messID, month string) ([]domain.Payment, error) {
+	filter := bson.M{
+		"mess_id":       messID,
+		"settles_month": bson.M{"$lte": month},
+		"month":         bson.M{"$gt": month},
+	}
+	cursor, err := r.db.Collection("payments").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var payments []domain.Payment
+	if err = cursor.All(ctx, &payments); err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
 // --- Bazar ---
 func (r *FinanceRepository) CreateBazar(ctx context.Context, bazar *domain.Bazar) error {
 	_, err := r.db.Collection("bazars").InsertOne(ctx, bazar)
@@ -110,7 +313,7 @@ func (r *FinanceRepository) GetBazarByID(ctx context.Context, bazarID string) (*
 }
 
 func (r *FinanceRepository) GetBazars(ctx context.Context, messID, month string) ([]domain.Bazar, error) {
-	filter := bson.M{"mess_id": messID, "month": month}
+	filter := bson.M{"mess_id": messID, "month": month, "deleted_at": notDeleted}
 	cursor, err := r.db.Collection("bazars").Find(ctx, filter)
 	if err != nil {
 		return nil, err
@@ -122,6 +325,21 @@ func (r *FinanceRepository) GetBazars(ctx context.Context, messID, month string)
 	return bazars, nil
 }
 
+func (r *FinanceRepository) GetBazarsInRange(ctx context.Context, messID, fromMonth, toMonth string) ([]domain.Bazar, error) {
+	// YYYY-MM keys sort lexically, so a string range covers the months
+	filter := bson.M{"mess_id": messID, "month": bson.M{"$gte": fromMonth, "$lte": toMonth}, "deleted_at": notDeleted}
+	opts := options.Find().SetSort(bson.M{"month": 1})
+	cursor, err := r.db.Collection("bazars").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var bazars []domain.Bazar
+	if err = cursor.All(ctx, &bazars); err != nil {
+		return nil, err
+	}
+	return bazars, nil
+}
+
 func (r *FinanceRepository) ApproveBazar(ctx context.Context, bazarID string) error {
 	filter := bson.M{"_id": bazarID}
 	update := bson.M{"$set": bson.M{"status": "approved"}}
@@ -139,12 +357,11 @@ func (r *FinanceRepository) UpsertDailyMeal(ctx context.Context, meal *domain.Da
 			"date":        meal.Date,
 			"user_id":     meal.UserID,
 			"mess_id":     meal.MessID,
-			"breakfast":   meal.Breakfast,
-			"lunch":       meal.Lunch,
-			"dinner":      meal.Dinner,
+			"meals":       meal.Meals,
 			"guest_meals": meal.GuestMeals,
 			"month":       meal.Month,
 		},
+		"$unset": bson.M{"breakfast": "", "lunch": "", "dinner": ""},
 	}
 
 	opts := options.Update().SetUpsert(true)
@@ -165,6 +382,133 @@ func (r *FinanceRepository) GetDailyMeals(ctx context.Context, messID, month str
 	return meals, nil
 }
 
+func (r *FinanceRepository) GetDailyMealsForDate(ctx context.Context, messID string, date time.Time) ([]domain.DailyMeal, error) {
+	filter := bson.M{"mess_id": messID, "date": date}
+	cursor, err := r.db.Collection("daily_meals").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var meals []domain.DailyMeal
+	if err = cursor.All(ctx, &meals); err != nil {
+		return nil, err
+	}
+	return meals, nil
+}
+
+// InsertDailyMealIfMissing creates the day's row unless the member already
+// has one, and reports whether it was created.
+func (r *FinanceRepository) InsertDailyMealIfMissing(ctx context.Context, meal *domain.DailyMeal) (bool, error) {
+	filter := bson.M{"date": meal.Date, "user_id": meal.UserID, "mess_id": meal.MessID}
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"date":        meal.Date,
+			"user_id":     meal.UserID,
+			"mess_id":     meal.MessID,
+			"meals":       meal.Meals,
+			"guest_meals": meal.GuestMeals,
+			"month":       meal.Month,
+		},
+	}
+	opts := options.Update().SetUpsert(true)
+	res, err := r.db.Collection("daily_meals").UpdateOne(ctx, filter, update, opts)
+	if err != nil {
+		return false, err
+	}
+	return res.UpsertedCount > 0, nil
+}
+
+// --- Meal Schedules ---
+func (r *FinanceRepository) UpsertMealSchedule(ctx context.Context, schedule *domain.MealSchedule) error {
+	filter := bson.M{"mess_id": schedule.MessID, "user_id": schedule.UserID}
+	update := bson.M{
+		"$set": bson.M{
+			"meals":      schedule.Meals,
+			"active":     schedule.Active,
+			"updated_by": schedule.UpdatedBy,
+			"updated_at": schedule.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{"_id": schedule.ID},
+	}
+	opts := options.Update().SetUpsert(true)
+	_, err := r.db.Collection("meal_schedules").UpdateOne(ctx, filter, update, opts)
+	return err
+}
+
+func (r *FinanceRepository) GetMealSchedule(ctx context.Context, messID, userID string) (*domain.MealSchedule, error) {
+	var schedule domain.MealSchedule
+	filter := bson.M{"mess_id": messID, "user_id": userID}
+	err := r.db.Collection("meal_schedules").FindOne(ctx, filter).Decode(&schedule)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (r *FinanceRepository) GetMealSchedules(ctx context.Context, messID string) ([]domain.MealSchedule, error) {
+	cursor, err := r.db.Collection("meal_schedules").Find(ctx, bson.M{"mess_id": messID})
+	if err != nil {
+		return nil, err
+	}
+	var schedules []domain.MealSchedule
+	if err = cursor.All(ctx, &schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// GetActiveMealSchedules returns the active schedules of every mess.
+func (r *FinanceRepository) GetActiveMealSchedules(ctx context.Context) ([]domain.MealSchedule, error) {
+	cursor, err := r.db.Collection("meal_schedules").Find(ctx, bson.M{"active": true})
+	if err != nil {
+		return nil, err
+	}
+	var schedules []domain.MealSchedule
+	if err = cursor.All(ctx, &schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (r *FinanceRepository) CreateMealOff(ctx context.Context, off *domain.MealOff) error {
+	_, err := r.db.Collection("meal_offs").InsertOne(ctx, off)
+	return err
+}
+
+func (r *FinanceRepository) GetMealOffByID(ctx context.Context, offID string) (*domain.MealOff, error) {
+	var off domain.MealOff
+	err := r.db.Collection("meal_offs").FindOne(ctx, bson.M{"_id": offID}).Decode(&off)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &off, nil
+}
+
+// GetMealOffs returns the meal-offs of the mess that overlap the date range.
+func (r *FinanceRepository) GetMealOffs(ctx context.Context, messID string, from, to time.Time) ([]domain.MealOff, error) {
+	filter := bson.M{"mess_id": messID, "from": bson.M{"$lte": to}, "to": bson.M{"$gte": from}}
+	opts := options.Find().SetSort(bson.D{{Key: "from", Value: 1}})
+	cursor, err := r.db.Collection("meal_offs").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var offs []domain.MealOff
+	if err = cursor.All(ctx, &offs); err != nil {
+		return nil, err
+	}
+	return offs, nil
+}
+
+func (r *FinanceRepository) DeleteMealOff(ctx context.Context, offID string) error {
+	_, err := r.db.Collection("meal_offs").DeleteOne(ctx, bson.M{"_id": offID})
+	return err
+}
+
 // --- Month Lock ---
 func (r *FinanceRepository) GetMonthLock(ctx context.Context, messID, month string) (*domain.MonthLock, error) {
 	var lock domain.MonthLock
@@ -181,27 +525,199 @@ func (r *FinanceRepository) GetMonthLock(ctx context.Context, messID, month stri
 
 func (r *FinanceRepository) UpsertMonthLock(ctx context.Context, lock *domain.MonthLock) error {
 	filter := bson.M{"mess_id": lock.MessID, "month": lock.Month}
-	update := bson.M{"$set": lock}
+	// Set fields explicitly so a cleared UnlockExpiry is not skipped by omitempty
+	update := bson.M{
+		"$set": bson.M{
+			"is_locked":        lock.IsLocked,
+			"unlock_requested": lock.UnlockRequested,
+			"unlock_expiry":    lock.UnlockExpiry,
+		},
+		"$setOnInsert": bson.M{"_id": lock.ID},
+	}
 	opts := options.Update().SetUpsert(true)
 	_, err := r.db.Collection("month_locks").UpdateOne(ctx, filter, update, opts)
 	return err
 }
 
+// --- Summary Snapshots ---
+func (r *FinanceRepository) CreateSummarySnapshot(ctx context.Context, snapshot *domain.SummarySnapshot) error {
+	_, err := r.db.Collection("summary_snapshots").InsertOne(ctx, snapshot)
+	return err
+}
+
+func (r *FinanceRepository) GetLatestSummarySnapshot(ctx context.Context, messID, month string) (*domain.SummarySnapshot, error) {
+	var snapshot domain.SummarySnapshot
+	filter := bson.M{"mess_id": messID, "month": month}
+	opts := options.FindOne().SetSort(bson.M{"version": -1})
+	err := r.db.Collection("summary_snapshots").FindOne(ctx, filter, opts).Decode(&snapshot)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+func (r *FinanceRepository) GetSummarySnapshots(ctx context.Context, messID, month string) ([]domain.SummarySnapshot, error) {
+	filter := bson.M{"mess_id": messID, "month": month}
+	opts := options.Find().SetSort(bson.M{"version": 1})
+	cursor, err := r.db.Collection("summary_snapshots").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []domain.SummarySnapshot
+	if err = cursor.All(ctx, &snapshots); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// GetLastClosedMonth returns the latest month of the mess with a snapshot,
+// or "" if no month was ever closed.
+func (r *FinanceRepository) GetLastClosedMonth(ctx context.Context, messID string) (string, error) {
+	var snapshot domain.SummarySnapshot
+	opts := options.FindOne().SetSort(bson.M{"month": -1}).SetProjection(bson.M{"month": 1})
+	err := r.db.Collection("summary_snapshots").FindOne(ctx, bson.M{"mess_id": messID}, opts).Decode(&snapshot)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return "", nil
+		}
+		return "", err
+	}
+	return snapshot.Month, nil
+}
+
+// --- Opening Balances ---
+func (r *FinanceRepository) UpsertOpeningBalance(ctx context.Context, balance *domain.OpeningBalance) error {
+	filter := bson.M{"mess_id": balance.MessID, "user_id": balance.UserID, "month": balance.Month}
+	update := bson.M{
+		"$set": bson.M{
+			"source_month":   balance.SourceMonth,
+			"source_version": balance.SourceVersion,
+			"house_balance":  balance.HouseBalance,
+			"meal_balance":   balance.MealBalance,
+			"balance":        balance.Balance,
+			"created_at":     balance.CreatedAt,
+		},
+		"$setOnInsert": bson.M{"_id": balance.ID},
+	}
+	opts := options.Update().SetUpsert(true)
+	_, err := r.db.Collection("opening_balances").UpdateOne(ctx, filter, update, opts)
+	return err
+}
+
+func (r *FinanceRepository) GetOpeningBalances(ctx context.Context, messID, month string) ([]domain.OpeningBalance, error) {
+	filter := bson.M{"mess_id": messID, "month": month}
+	cursor, err := r.db.Collection("opening_balances").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var balances []domain.OpeningBalance
+	if err = cursor.All(ctx, &balances); err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+func (r *FinanceRepository) GetMemberOpeningBalances(ctx context.Context, messID, userID string) ([]domain.OpeningBalance, error) {
+	filter := bson.M{"mess_id": messID, "user_id": userID}
+	opts := options.Find().SetSort(bson.M{"month": 1})
+	cursor, err := r.db.Collection("opening_balances").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var balances []domain.OpeningBalance
+	if err = cursor.All(ctx, &balances); err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// --- Settlement ---
+func (r *FinanceRepository) CreateSettlementTransfer(ctx context.Context, transfer *domain.SettlementTransfer) error {
+	_, err := r.db.Collection("settlement_transfers").InsertOne(ctx, transfer)
+	return err
+}
+
+func (r *FinanceRepository) GetSettlementTransfers(ctx context.Context, messID, month string) ([]domain.SettlementTransfer, error) {
+	filter := bson.M{"mess_id": messID, "month": month}
+	opts := options.Find().SetSort(bson.M{"completed_at": 1})
+	cursor, err := r.db.Collection("settlement_transfers").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var transfers []domain.SettlementTransfer
+	if err = cursor.All(ctx, &transfers); err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
+
 func (r *FinanceRepository) UpdateBazar(ctx context.Context, bazar *domain.Bazar) error {
 	filter := bson.M{"_id": bazar.ID}
 	update := bson.M{"$set": bson.M{
-		"amount":   bazar.Amount,
-		"items":    bazar.Items,
-		"status":   bazar.Status,
-		"buyer_id": bazar.BuyerID,
-		"date":     bazar.Date,
+		"amount":     bazar.Amount,
+		"items":      bazar.Items,
+		"line_items": bazar.LineItems,
+		"status":     bazar.Status,
+		"buyer_id":   bazar.BuyerID,
+		"date":       bazar.Date,
 	}}
 	_, err := r.db.Collection("bazars").UpdateOne(ctx, filter, update)
 	return err
 }
 
-func (r *FinanceRepository) DeleteBazar(ctx context.Context, bazarID string) error {
+// DeleteBazar moves an entry to the trash. PurgeBazar removes it for good.
+func (r *FinanceRepository) DeleteBazar(ctx context.Context, bazarID, deletedBy string, deletedAt time.Time) error {
+	filter := bson.M{"_id": bazarID}
+	update := bson.M{"$set": bson.M{"deleted_at": deletedAt, "deleted_by": deletedBy}}
+	_, err := r.db.Collection("bazars").UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *FinanceRepository) RestoreBazar(ctx context.Context, bazarID string) error {
 	filter := bson.M{"_id": bazarID}
-	_, err := r.db.Collection("bazars").DeleteOne(ctx, filter)
+	update := bson.M{"$unset": bson.M{"deleted_at": "", "deleted_by": ""}}
+	_, err := r.db.Collection("bazars").UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *FinanceRepository) GetDeletedBazars(ctx context.Context, messID string) ([]domain.Bazar, error) {
+	filter := bson.M{"mess_id": messID, "deleted_at": bson.M{"$exists": true}}
+	opts := options.Find().SetSort(bson.M{"deleted_at": -1})
+	cursor, err := r.db.Collection("bazars").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var bazars []domain.Bazar
+	if err = cursor.All(ctx, &bazars); err != nil {
+		return nil, err
+	}
+	return bazars, nil
+}
+
+func (r *FinanceRepository) GetBazarsDeletedBefore(ctx context.Context, before time.Time) ([]domain.Bazar, error) {
+	filter := bson.M{"deleted_at": bson.M{"$lt": before}}
+	cursor, err := r.db.Collection("bazars").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var bazars []domain.Bazar
+	if err = cursor.All(ctx, &bazars); err != nil {
+		return nil, err
+	}
+	return bazars, nil
+}
+
+func (r *FinanceRepository) PurgeBazar(ctx context.Context, bazarID string) error {
+	_, err := r.db.Collection("bazars").DeleteOne(ctx, bson.M{"_id": bazarID})
+	return err
+}
+
+func (r *FinanceRepository) AddBazarAttachment(ctx context.Context, bazarID, attachmentID string) error {
+	filter := bson.M{"_id": bazarID}
+	update := bson.M{"$addToSet": bson.M{"attachments": attachmentID}}
+	_, err := r.db.Collection("bazars").UpdateOne(ctx, filter, update)
 	return err
 }
@@ -0,0 +1,81 @@
+package mongo
+
+import (
+	"amar-dera/internal/core/domain"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type InviteRepository struct {
+	collection *mongo.Collection
+}
+
+func NewInviteRepository(db *mongo.Database) domain.InviteRepository {
+	return &InviteRepository{
+		collection: db.Collection("invites"),
+	}
+}
+
+func (r *InviteRepository) Create(ctx context.Context, invite *domain.Invite) error {
+	_, err := r.collection.InsertOne(ctx, invite)
+	return err
+}
+
+func (r *InviteRepository) GetByID(ctx context.Context, id string) (*domain.Invite, error) {
+	var invite domain.Invite
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&invite)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// ListByMess returns the mess's invites, newest first.
+func (r *InviteRepository) ListByMess(ctx context.Context, messID string) ([]domain.Invite, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"mess_id": messID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var invites []domain.Invite
+	if err := cursor.All(ctx, &invites); err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
+
+func (r *InviteRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{"revoked_at": revokedAt}}
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *InviteRepository) Redeem(ctx context.Context, id, userID string, now time.Time) (bool, error) {
+	filter := bson.M{
+		"_id":        id,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+		"used_by":    bson.M{"$ne": userID},
+		"$expr":      bson.M{"$lt": bson.A{"$uses", "$max_uses"}},
+	}
+	update := bson.M{
+		"$inc":  bson.M{"uses": 1},
+		"$push": bson.M{"used_by": userID},
+	}
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
@@ -0,0 +1,94 @@
+package mongo
+
+import (
+	"amar-dera/internal/core/domain"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LedgerRepository struct {
+	collection *mongo.Collection
+}
+
+func NewLedgerRepository(db *mongo.Database) domain.LedgerRepository {
+	return &LedgerRepository{
+		collection: db.Collection("journal_entries"),
+	}
+}
+
+func (r *LedgerRepository) CreateEntry(ctx context.Context, entry *domain.JournalEntry) error {
+	_, err := r.collection.InsertOne(ctx, entry)
+	return err
+}
+
+func (r *LedgerRepository) GetEntries(ctx context.Context, messID, month string) ([]domain.JournalEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"mess_id": messID, "month": month}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []domain.JournalEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetEntriesThrough returns the mess's entries of every month up to and
+// including the given one.
+func (r *LedgerRepository) GetEntriesThrough(ctx context.Context, messID, month string) ([]domain.JournalEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"mess_id": messID, "month": bson.M{"$lte": month}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []domain.JournalEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *LedgerRepository) GetEntriesBySource(ctx context.Context, messID string, sourceType domain.JournalSource, sourceID string) ([]domain.JournalEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"mess_id": messID, "source_type": sourceType, "source_id": sourceID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []domain.JournalEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *LedgerRepository) GetAccountEntries(ctx context.Context, messID, account string) ([]domain.JournalEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"mess_id": messID, "lines.account": account}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []domain.JournalEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *LedgerRepository) MarkEntryReversed(ctx context.Context, entryID, reversalID string) error {
+	filter := bson.M{"_id": entryID}
+	update := bson.M{"$set": bson.M{"reversed_by": reversalID}}
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
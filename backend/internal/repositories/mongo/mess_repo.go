@@ -4,6 +4,7 @@ import (
 	"amar-dera/internal/core/domain"
 	"context"
 	"errors"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -49,3 +50,33 @@ func (r *MessRepository) AddMember(ctx context.Context, messID string, member do
 	_, err := r.collection.UpdateOne(ctx, filter, update)
 	return err
 }
+
+func (r *MessRepository) UpdateMealConfig(ctx context.Context, messID string, config domain.MealConfig) error {
+	filter := bson.M{"_id": messID}
+	update := bson.M{"$set": bson.M{"meal_config": config}}
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *MessRepository) RemovePendingMember(ctx context.Context, messID, userID string) error {
+	filter := bson.M{"_id": messID}
+	update := bson.M{"$pull": bson.M{"members": bson.M{"user_id": userID, "status": "pending"}}}
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *MessRepository) GetWithPendingBefore(ctx context.Context, before time.Time) ([]domain.Mess, error) {
+	filter := bson.M{"members": bson.M{"$elemMatch": bson.M{
+		"status":    "pending",
+		"joined_at": bson.M{"$lt": before},
+	}}}
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var messes []domain.Mess
+	if err := cursor.All(ctx, &messes); err != nil {
+		return nil, err
+	}
+	return messes, nil
+}
@@ -0,0 +1,145 @@
+package mongo
+
+import (
+	"amar-dera/internal/core/domain"
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// toPaisa converts a float taka field to integer paisa inside an update pipeline.
+func toPaisa(field string) bson.M {
+	return bson.M{"$toLong": bson.M{"$round": bson.A{bson.M{"$multiply": bson.A{field, 100}}, 0}}}
+}
+
+// MigrateMoneyToPaisa rewrites amounts stored as float taka into integer
+// paisa. Only documents still holding doubles are touched, so it is safe to
+// run on every start. Snapshots keep their nested float summaries and are
+// converted when read (see domain.Money).
+func MigrateMoneyToPaisa(ctx context.Context, db *mongo.Database) error {
+	flat := map[string][]string{
+		"payments":             {"amount"},
+		"bazars":               {"amount"},
+		"service_costs":        {"amount"},
+		"settlement_transfers": {"amount"},
+		"opening_balances":     {"house_balance", "meal_balance", "balance"},
+	}
+	for collection, fields := range flat {
+		for _, field := range fields {
+			filter := bson.M{field: bson.M{"$type": "double"}}
+			pipeline := mongo.Pipeline{{{Key: "$set", Value: bson.M{field: toPaisa("$" + field)}}}}
+			res, err := db.Collection(collection).UpdateMany(ctx, filter, pipeline)
+			if err != nil {
+				return err
+			}
+			if res.ModifiedCount > 0 {
+				log.Printf("Migrated %d %s.%s values to paisa", res.ModifiedCount, collection, field)
+			}
+		}
+	}
+
+	// Custom service cost splits
+	shares := mongo.Pipeline{{{Key: "$set", Value: bson.M{"shares": bson.M{"$map": bson.M{
+		"input": "$shares",
+		"as":    "s",
+		"in":    bson.M{"user_id": "$$s.user_id", "amount": toPaisa("$$s.amount")},
+	}}}}}}
+	if _, err := db.Collection("service_costs").UpdateMany(ctx, bson.M{"shares.amount": bson.M{"$type": "double"}}, shares); err != nil {
+		return err
+	}
+
+	// Journal lines
+	lines := mongo.Pipeline{{{Key: "$set", Value: bson.M{"lines": bson.M{"$map": bson.M{
+		"input": "$lines",
+		"as":    "l",
+		"in":    bson.M{"account": "$$l.account", "debit": toPaisa("$$l.debit"), "credit": toPaisa("$$l.credit")},
+	}}}}}}
+	filter := bson.M{"$or": bson.A{
+		bson.M{"lines.debit": bson.M{"$type": "double"}},
+		bson.M{"lines.credit": bson.M{"$type": "double"}},
+	}}
+	if _, err := db.Collection("journal_entries").UpdateMany(ctx, filter, lines); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MigrateMealSlots moves the fixed breakfast/lunch/dinner fields of daily
+// meals into the configurable meals map. Documents already migrated have no
+// breakfast field, so it is safe to run on every start.
+func MigrateMealSlots(ctx context.Context, db *mongo.Database) error {
+	filter := bson.M{"breakfast": bson.M{"$exists": true}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"meals": bson.M{"$arrayToObject": bson.M{"$filter": bson.M{
+			"input": bson.A{
+				bson.M{"k": "breakfast", "v": bson.M{"$ifNull": bson.A{"$breakfast", 0}}},
+				bson.M{"k": "lunch", "v": bson.M{"$ifNull": bson.A{"$lunch", 0}}},
+				bson.M{"k": "dinner", "v": bson.M{"$ifNull": bson.A{"$dinner", 0}}},
+			},
+			"as":   "slot",
+			"cond": bson.M{"$ne": bson.A{"$$slot.v", 0}},
+		}}}}}},
+		{{Key: "$unset", Value: bson.A{"breakfast", "lunch", "dinner"}}},
+	}
+	res, err := db.Collection("daily_meals").UpdateMany(ctx, filter, pipeline)
+	if err != nil {
+		return err
+	}
+	if res.ModifiedCount > 0 {
+		log.Printf("Migrated %d daily meals to meal slots", res.ModifiedCount)
+	}
+	return nil
+}
+
+// MigrateMessOwners points admin_id at an active admin for messes whose
+// owner has left or lost the admin role, which used to go unnoticed.
+// Messes without any active admin are left alone.
+func MigrateMessOwners(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection("messes")
+	cursor, err := collection.Find(ctx, bson.M{"dissolved_at": bson.M{"$exists": false}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var messes []domain.Mess
+	if err := cursor.All(ctx, &messes); err != nil {
+		return err
+	}
+	fixed := 0
+	for _, mess := range messes {
+		if owner := mess.FindMember(mess.AdminID); owner != nil && owner.Status == "active" && owner.HasRole(domain.RoleAdmin) {
+			continue
+		}
+		next := mess.ActiveAdminOtherThan(mess.AdminID)
+		if next == nil {
+			continue
+		}
+		if _, err := collection.UpdateOne(ctx, bson.M{"_id": mess.ID}, bson.M{"$set": bson.M{"admin_id": next.UserID}}); err != nil {
+			return err
+		}
+		fixed++
+	}
+	if fixed > 0 {
+		log.Printf("Moved ownership of %d messes to an active admin", fixed)
+	}
+	return nil
+}
+
+// EnsureIndexes creates the indexes the repositories rely on for
+// uniqueness. Creating an index that already exists is a no-op, so it is safe
+// to run on every start.
+func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	// One copy of a cost template per month, even when the scheduler and a
+	// manager apply it at the same time
+	_, err := db.Collection("service_costs").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "mess_id", Value: 1}, {Key: "template_id", Value: 1}, {Key: "month", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"template_id": bson.M{"$type": "string"}}),
+	})
+	return err
+}
@@ -0,0 +1,60 @@
+package mongo
+
+import (
+	"amar-dera/internal/core/domain"
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Transactor runs units of work in MongoDB transactions. Transactions need a
+// replica set or sharded cluster; on a standalone server it falls back to
+// running the work directly, without atomicity.
+type Transactor struct {
+	client    *mongo.Client
+	supported bool
+}
+
+func NewTransactor(db *mongo.Database) domain.Transactor {
+	supported := supportsTransactions(db)
+	if !supported {
+		log.Println("MongoDB is a standalone server; multi-document writes will not be transactional")
+	}
+	return &Transactor{client: db.Client(), supported: supported}
+}
+
+func (t *Transactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !t.supported {
+		return fn(ctx)
+	}
+	// Already inside a transaction: join it instead of nesting
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	session, err := t.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
+
+// supportsTransactions reports whether the deployment is a replica set
+// member or a mongos router.
+func supportsTransactions(db *mongo.Database) bool {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := db.RunCommand(context.Background(), bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false
+	}
+	return hello.SetName != "" || hello.Msg == "isdbgrid"
+}
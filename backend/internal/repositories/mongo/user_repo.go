@@ -4,6 +4,7 @@ import (
 	"amar-dera/internal/core/domain"
 	"context"
 	"errors"
+	"fmt"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -66,3 +67,36 @@ func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 	_, err := r.collection.UpdateOne(ctx, filter, update)
 	return err
 }
+
+// AddMess adds the mess to the user's messes and drops any join request or
+// rejection for it.
+func (r *UserRepository) AddMess(ctx context.Context, userID, messID string) error {
+	filter := bson.M{"_id": userID}
+	update := bson.M{
+		"$addToSet": bson.M{"messes": messID},
+		"$pull":     bson.M{"join_requests": messID, "join_rejections": bson.M{"mess_id": messID}},
+	}
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("user %w", domain.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *UserRepository) SetCurrentMess(ctx context.Context, userID, messID string) error {
+	filter := bson.M{"_id": userID}
+	update := bson.M{"$set": bson.M{"current_mess_id": messID}}
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// SetCurrentMessIfUnset makes the mess current only for a user without one.
+func (r *UserRepository) SetCurrentMessIfUnset(ctx context.Context, userID, messID string) error {
+	filter := bson.M{"_id": userID, "current_mess_id": bson.M{"$in": bson.A{nil, ""}}}
+	update := bson.M{"$set": bson.M{"current_mess_id": messID}}
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
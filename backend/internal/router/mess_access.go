@@ -0,0 +1,82 @@
+package router
+
+import (
+	"amar-dera/internal/core/domain"
+	"amar-dera/internal/core/services"
+	"amar-dera/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MessAccessMiddleware resolves the mess named by the :id path parameter and
+// lets the request through only for its active members, or read-only for
+// suspended members and for former members once the mess is dissolved. The mess and the caller's
+// member entry are stored in the gin context as "mess" and "member", and the
+// mess in the request context for services.
+func MessAccessMiddleware(messService *services.MessService) gin.HandlerFunc {
+	return messAccess(messService, false)
+}
+
+// SettlementAccessMiddleware is MessAccessMiddleware for settling balances,
+// which former members can still do once the mess is dissolved.
+func SettlementAccessMiddleware(messService *services.MessService) gin.HandlerFunc {
+	return messAccess(messService, true)
+}
+
+func messAccess(messService *services.MessService, settlement bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		messID := c.Param("id")
+		mess, err := messService.GetByID(c.Request.Context(), messID)
+		if err != nil || mess == nil {
+			utils.SendError(c, http.StatusNotFound, "mess not found", err)
+			c.Abort()
+			return
+		}
+
+		member := mess.FindMember(c.GetString("userID"))
+		if mess.IsDissolved() {
+			// The books of a dissolved mess stay readable to its former members
+			if member == nil || member.Status != "left" {
+				utils.SendError(c, http.StatusForbidden, "you are not a member of this mess", nil)
+				c.Abort()
+				return
+			}
+			if c.Request.Method != http.MethodGet && !settlement {
+				utils.SendError(c, http.StatusForbidden, "this mess has been dissolved and its books are read-only", nil)
+				c.Abort()
+				return
+			}
+		} else if member != nil && member.Status == "suspended" {
+			// Suspended members can still see the mess, but not change anything
+			if c.Request.Method != http.MethodGet {
+				utils.SendError(c, http.StatusForbidden, "your membership of this mess is suspended", nil)
+				c.Abort()
+				return
+			}
+		} else if member == nil || member.Status != "active" {
+			utils.SendError(c, http.StatusForbidden, "you are not a member of this mess", nil)
+			c.Abort()
+			return
+		}
+
+		c.Set("mess", mess)
+		c.Set("member", member)
+		c.Request = c.Request.WithContext(domain.WithMess(c.Request.Context(), mess))
+		c.Next()
+	}
+}
+
+// RequireMessRole allows the request only if the member injected by
+// MessAccessMiddleware holds one of the roles.
+func RequireMessRole(roles ...domain.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		member, ok := c.Get("member")
+		if !ok || !member.(*domain.Member).HasRole(roles...) {
+			utils.SendError(c, http.StatusForbidden, "you do not have permission for this action", nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
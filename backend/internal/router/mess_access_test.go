@@ -0,0 +1,104 @@
+package router
+
+import (
+	"amar-dera/internal/core/domain"
+	"amar-dera/internal/core/services"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeMessRepo struct {
+	domain.MessRepository
+	messes map[string]*domain.Mess
+}
+
+func (r *fakeMessRepo) GetByID(ctx context.Context, id string) (*domain.Mess, error) {
+	return r.messes[id], nil
+}
+
+// newAccessRouter serves one member route and one manager route behind the
+// mess middlewares. The caller is taken from the X-User header in place of
+// the JWT.
+func newAccessRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	repo := &fakeMessRepo{messes: map[string]*domain.Mess{
+		"MESS-1": {ID: "MESS-1", Members: []domain.Member{
+			{UserID: "manager", Roles: []domain.Role{domain.RoleMember, domain.RoleManager}, Status: "active"},
+			{UserID: "member", Roles: []domain.Role{domain.RoleMember}, Status: "active"},
+			{UserID: "suspended", Roles: []domain.Role{domain.RoleMember, domain.RoleManager}, Status: "suspended"},
+			{UserID: "pending", Roles: []domain.Role{domain.RoleMember}, Status: "pending"},
+			{UserID: "left", Roles: []domain.Role{domain.RoleMember, domain.RoleManager}, Status: "left"},
+		}},
+		"MESS-2": {ID: "MESS-2", Members: []domain.Member{
+			{UserID: "outsider", Roles: []domain.Role{domain.RoleMember, domain.RoleAdmin}, Status: "active"},
+		}},
+	}}
+	messService := services.NewMessService(repo, nil, nil, nil, nil, nil)
+
+	ok := func(c *gin.Context) {
+		if domain.MessFromContext(c.Request.Context(), c.Param("id")) == nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("userID", c.GetHeader("X-User")) })
+	mess := r.Group("/messes/:id", MessAccessMiddleware(messService))
+	mess.GET("/meals", ok)
+	mess.POST("/costs", RequireMessRole(domain.RoleManager, domain.RoleAdmin), ok)
+	return r
+}
+
+func TestMessAccessMiddleware(t *testing.T) {
+	r := newAccessRouter()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		user   string
+		want   int
+	}{
+		{name: "member reads", method: http.MethodGet, path: "/messes/MESS-1/meals", user: "member", want: http.StatusOK},
+		{name: "manager writes", method: http.MethodPost, path: "/messes/MESS-1/costs", user: "manager", want: http.StatusOK},
+		{name: "member of another mess", method: http.MethodGet, path: "/messes/MESS-1/meals", user: "outsider", want: http.StatusForbidden},
+		{name: "admin of another mess cannot write", method: http.MethodPost, path: "/messes/MESS-1/costs", user: "outsider", want: http.StatusForbidden},
+		{name: "suspended member reads", method: http.MethodGet, path: "/messes/MESS-1/meals", user: "suspended", want: http.StatusOK},
+		{name: "suspended manager cannot write", method: http.MethodPost, path: "/messes/MESS-1/costs", user: "suspended", want: http.StatusForbidden},
+		{name: "pending member", method: http.MethodGet, path: "/messes/MESS-1/meals", user: "pending", want: http.StatusForbidden},
+		{name: "member who left", method: http.MethodGet, path: "/messes/MESS-1/meals", user: "left", want: http.StatusForbidden},
+		{name: "manager who left cannot write", method: http.MethodPost, path: "/messes/MESS-1/costs", user: "left", want: http.StatusForbidden},
+		{name: "wrong role", method: http.MethodPost, path: "/messes/MESS-1/costs", user: "member", want: http.StatusForbidden},
+		{name: "unknown mess", method: http.MethodGet, path: "/messes/MESS-9/meals", user: "member", want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("X-User", tt.user)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("%s %s as %s: got %d, want %d", tt.method, tt.path, tt.user, w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireMessRoleWithoutMember(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/", RequireMessRole(domain.RoleManager), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("got %d without the mess middleware, want 403", w.Code)
+	}
+}
@@ -1,6 +1,8 @@
 package router
 
 import (
+	"amar-dera/pkg/utils"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -19,6 +21,9 @@ func RequestIDMiddleware() gin.HandlerFunc {
 		// Set in context for other middlewares/handlers
 		c.Set("requestID", requestID)
 
+		// And in the request context for services (audit log)
+		c.Request = c.Request.WithContext(utils.WithRequestID(c.Request.Context(), requestID))
+
 		c.Next()
 	}
 }
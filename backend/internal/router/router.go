@@ -2,6 +2,8 @@ package router
 
 import (
 	"amar-dera/config"
+	"amar-dera/internal/core/domain"
+	"amar-dera/internal/core/services"
 	"amar-dera/internal/handlers"
 
 	"github.com/gin-gonic/gin"
@@ -13,6 +15,10 @@ func NewRouter(
 	messHandler *handlers.MessHandler,
 	financeHandler *handlers.FinanceHandler,
 	feedHandler *handlers.FeedHandler,
+	ledgerHandler *handlers.LedgerHandler,
+	attachmentHandler *handlers.AttachmentHandler,
+	auditHandler *handlers.AuditHandler,
+	messService *services.MessService,
 ) *gin.Engine {
 	r := gin.New() // Use New instead of Default to avoid default logger
 
@@ -46,6 +52,11 @@ func NewRouter(
 		protected := api.Group("/")
 		protected.Use(AuthMiddleware(cfg))
 		{
+			// Routes under a mess :id are only for its active members
+			member := MessAccessMiddleware(messService)
+			settle := SettlementAccessMiddleware(messService)
+			admin := RequireMessRole(domain.RoleAdmin)
+
 			// User
 			protected.GET("/users/me", authHandler.Me)
 
@@ -54,31 +65,67 @@ func NewRouter(
 			{
 				messGroup.POST("/create", messHandler.CreateMess)
 				messGroup.POST("/join", messHandler.JoinMess)
-				messGroup.GET("/:id/requests", messHandler.GetRequests)
-				messGroup.GET("/:id/details", messHandler.GetMessDetails)
-				messGroup.PATCH("/:id/requests/approve", messHandler.ApproveMember)
-				messGroup.PATCH("/:id/roles", messHandler.AssignRole)
-				messGroup.DELETE("/:id/roles", messHandler.RemoveRole)
-				messGroup.POST("/:id/leave", messHandler.LeaveMess)
+				messGroup.GET("/:id/requests", member, admin, messHandler.GetRequests)
+				messGroup.GET("/:id/details", member, messHandler.GetMessDetails)
+				messGroup.PATCH("/:id/requests/approve", member, admin, messHandler.ApproveMember)
+				messGroup.PATCH("/:id/requests/reject", member, admin, messHandler.RejectMember)
+				messGroup.DELETE("/:id/join", messHandler.CancelJoinRequest) // Requester is still pending
+				messGroup.GET("/:id/invites", member, admin, messHandler.GetInvites)
+				messGroup.POST("/:id/invites", member, admin, messHandler.CreateInvite)
+				messGroup.DELETE("/:id/invites/:inviteId", member, admin, messHandler.RevokeInvite)
+				messGroup.PATCH("/:id/roles", member, admin, messHandler.AssignRole)
+				messGroup.DELETE("/:id/roles", member, admin, messHandler.RemoveRole)
+				messGroup.POST("/:id/leave", member, messHandler.LeaveMess)
+				messGroup.POST("/:id/switch", messHandler.SwitchMess) // Suspended members can switch too
+				messGroup.DELETE("/:id/members/:userId", member, admin, messHandler.RemoveMember)
+				messGroup.POST("/:id/members/:userId/suspend", member, admin, messHandler.SuspendMember)
+				messGroup.POST("/:id/members/:userId/reinstate", member, admin, messHandler.ReinstateMember)
+				messGroup.POST("/:id/ownership/transfer", member, admin, messHandler.TransferOwnership)
+				messGroup.DELETE("/:id/ownership/transfer", member, messHandler.CancelOwnershipTransfer)
+				messGroup.POST("/:id/ownership/accept", member, messHandler.AcceptOwnership)
+				messGroup.POST("/:id/dissolve", member, admin, messHandler.DissolveMess)
+				messGroup.GET("/:id/meal-config", member, messHandler.GetMealConfig)
+				messGroup.PUT("/:id/meal-config", member, messHandler.UpdateMealConfig)
 			}
 
+			// Invites are addressed by token, not by mess
+			protected.GET("/invites/:token", messHandler.GetInvitePreview)
+			protected.POST("/invites/:token/accept", messHandler.AcceptInvite)
+
 			// Finance - House
-			houseGroup := protected.Group("/house")
+			houseGroup := protected.Group("/house", member)
 			{
 				houseGroup.GET("/:id/costs", financeHandler.GetServiceCosts)
 				houseGroup.POST("/:id/costs", financeHandler.AddServiceCost)
+				houseGroup.PATCH("/:id/costs/:costId", financeHandler.UpdateServiceCost)
 				houseGroup.DELETE("/:id/costs/:costId", financeHandler.DeleteServiceCost)
+				houseGroup.GET("/:id/trash", financeHandler.GetDeletedServiceCosts)
+				houseGroup.POST("/:id/costs/:costId/restore", financeHandler.RestoreServiceCost)
+				houseGroup.POST("/:id/costs/:costId/attachments", attachmentHandler.UploadServiceCostAttachment)
+				houseGroup.GET("/:id/costs/:costId/attachments", attachmentHandler.ListServiceCostAttachments)
+				houseGroup.GET("/:id/templates", financeHandler.GetCostTemplates)
+				houseGroup.POST("/:id/templates", financeHandler.CreateCostTemplate)
+				houseGroup.PATCH("/:id/templates/:templateId", financeHandler.UpdateCostTemplate)
+				houseGroup.DELETE("/:id/templates/:templateId", financeHandler.DeleteCostTemplate)
+				houseGroup.POST("/:id/templates/apply", financeHandler.ApplyCostTemplates)
 			}
 
 			// Meals
-			mealGroup := protected.Group("/meals")
+			mealGroup := protected.Group("/meals", member)
 			{
 				mealGroup.GET("/:id/daily", financeHandler.GetDailyMeals)
 				mealGroup.POST("/:id/update", financeHandler.BatchUpdateMeals)
+				mealGroup.GET("/:id/schedule", financeHandler.GetMealSchedule)
+				mealGroup.PUT("/:id/schedule", financeHandler.SetMealSchedule)
+				mealGroup.GET("/:id/schedules", financeHandler.GetMealSchedules)
+				mealGroup.POST("/:id/off", financeHandler.CreateMealOff)
+				mealGroup.GET("/:id/off", financeHandler.GetMealOffs)
+				mealGroup.DELETE("/:id/off/:offId", financeHandler.CancelMealOff)
+				mealGroup.GET("/:id/headcount", financeHandler.GetMealHeadcount)
 			}
 
 			// Bazar
-			bazarGroup := protected.Group("/bazar")
+			bazarGroup := protected.Group("/bazar", member)
 			{
 				bazarGroup.POST("/:id/entry", financeHandler.CreateBazar)
 				bazarGroup.GET("/:id/pending", financeHandler.GetPendingBazars)
@@ -86,34 +133,65 @@ func NewRouter(
 				bazarGroup.PATCH("/:id/approve/:bazarId", financeHandler.ApproveBazar)
 				bazarGroup.PATCH("/:id/entry/:bazarId", financeHandler.UpdateBazar)
 				bazarGroup.DELETE("/:id/entry/:bazarId", financeHandler.DeleteBazar)
+				bazarGroup.GET("/:id/trash", financeHandler.GetDeletedBazars)
+				bazarGroup.POST("/:id/entry/:bazarId/restore", financeHandler.RestoreBazar)
+				bazarGroup.POST("/:id/entry/:bazarId/attachments", attachmentHandler.UploadBazarAttachment)
+				bazarGroup.GET("/:id/entry/:bazarId/attachments", attachmentHandler.ListBazarAttachments)
+				bazarGroup.GET("/:id/report", financeHandler.GetBazarSpendReport)
 			}
 
 			// Payments
-			payGroup := protected.Group("/payments")
+			payGroup := protected.Group("/payments", member)
 			{
 				payGroup.POST("/:id/submit", financeHandler.SubmitPayment)
 				payGroup.GET("/:id/pending", financeHandler.GetPendingPayments)
 				payGroup.GET("/:id/my-history", financeHandler.GetMemberPayments)
 				payGroup.GET("/:id/all-history", financeHandler.GetMessPayments)
 				payGroup.PATCH("/:id/verify/:payId", financeHandler.VerifyPayment)
+				payGroup.PATCH("/:id/reject/:payId", financeHandler.RejectPayment)
+				payGroup.POST("/:id/reverse/:payId", financeHandler.ReversePayment)
 			}
 
 			// Summary
-			summaryGroup := protected.Group("/summary")
+			summaryGroup := protected.Group("/summary", member)
 			{
 				summaryGroup.GET("/:id/fixed", financeHandler.GetMonthSummary)
 				summaryGroup.GET("/:id/meals", financeHandler.GetMonthSummary)
-				summaryGroup.GET("/:id/final", financeHandler.GetMonthSummary)
+				summaryGroup.GET("/:id/final", financeHandler.GetFinalSummary)
+				summaryGroup.POST("/:id/close", financeHandler.CloseMonth)
+				summaryGroup.GET("/:id/snapshots", financeHandler.GetSummarySnapshots)
+				summaryGroup.GET("/:id/balances", financeHandler.GetBalanceHistory)
+				summaryGroup.GET("/:id/settlement", financeHandler.GetSettlementPlan)
+				summaryGroup.GET("/:id/settlement/final/:userId", financeHandler.GetFinalSettlement)
 			}
 
+			// Settling balances stays open after the mess is dissolved
+			protected.POST("/payments/:id/refund", settle, financeHandler.RefundMember)
+			protected.POST("/summary/:id/settlement/done", settle, financeHandler.CompleteSettlementTransfer)
+
 			// History
-			histGroup := protected.Group("/history")
+			histGroup := protected.Group("/history", member)
 			{
 				histGroup.POST("/:id/unlock-request", financeHandler.RequestUnlock)
 				histGroup.GET("/:id/pending-requests", financeHandler.GetLockStatus)
 				histGroup.PATCH("/:id/lock-status", financeHandler.SetLockStatus)
 			}
 
+			// Ledger
+			ledgerGroup := protected.Group("/ledger", member)
+			{
+				ledgerGroup.GET("/:id/balances", ledgerHandler.GetTrialBalance)
+				ledgerGroup.GET("/:id/statement", ledgerHandler.GetStatement)
+				ledgerGroup.GET("/:id/integrity", ledgerHandler.CheckIntegrity)
+				ledgerGroup.POST("/:id/sync", ledgerHandler.SyncMonth)
+			}
+
+			// Attachments
+			protected.GET("/attachments/:id/:attachmentId", member, attachmentHandler.GetAttachment)
+
+			// Audit log
+			protected.GET("/audit/:id", member, admin, auditHandler.GetAuditLog)
+
 			// Feed
 			feed := protected.Group("/feed")
 			{
@@ -0,0 +1,17 @@
+package utils
+
+import "context"
+
+type requestIDKey struct{}
+
+// WithRequestID stores the request ID in the context so services can tag
+// what they record with it.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID, or "" outside a request.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}